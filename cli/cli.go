@@ -0,0 +1,460 @@
+// Package cli implements the sequin command-line tool.
+//
+// The tool operates a sequin deployment using the SequinService API. For
+// development, the serve command hosts the endpoints registered in the
+// binary, so worker binaries can call Main after registering their endpoints
+// to provide both a local server and a client:
+//
+//	func main() {
+//		cli.Main()
+//	}
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	sequinv1 "github.com/vgough/sequin/gen/sequin/v1"
+	"github.com/vgough/sequin/gen/sequin/v1/sequinv1connect"
+	"github.com/vgough/sequin/local"
+	"github.com/vgough/sequin/metrics"
+	"github.com/vgough/sequin/service"
+)
+
+// DefaultAddr is the default server address, unless set by the SEQUIN_ADDR
+// environment variable.
+const DefaultAddr = "http://localhost:8080"
+
+const usage = `usage: sequin [-addr url] <command> [arguments]
+
+Commands:
+  serve [-listen addr]      host the endpoints registered in this binary,
+                            with metrics at /metrics
+  list                      list endpoints
+  start [-label k=v]... <name> [json-args]
+                            start an operation and print its request ID
+  exec [-label k=v]... <name> [json-args]
+                            start an operation and wait for it to complete
+  get <request-id>          print the state of an operation
+  watch <request-id>        print the state of an operation until it completes
+  cancel <request-id>       cancel an operation
+  tree <request-id>         print an operation and the calls it made
+  ops [flags]               list operations, most recent first; flags:
+                              -endpoint name, -status running|succeeded|failed,
+                              -label k=v (repeatable), -since t, -until t,
+                              -top (top-level only), -limit n
+                            times are RFC 3339, or durations before now
+
+Arguments are a JSON array, or a JSON object for endpoints with argument names.
+`
+
+// errUsage is returned for invalid command lines.
+var errUsage = errors.New("invalid usage")
+
+// Main runs the command given by the process arguments, and exits.
+func Main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := Run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// Run runs the command given by args, returning the exit code.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("sequin", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	addr := fs.String("addr", defaultAddr(), "server `url`")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	c := &command{
+		client: sequinv1connect.NewSequinServiceClient(http.DefaultClient, *addr,
+			connect.WithInterceptors(service.NewTraceInterceptor(nil))),
+		stdout: stdout,
+		stderr: stderr,
+	}
+	err := c.run(ctx, fs.Arg(0), fs.Args()[1:])
+	switch {
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "sequin: %v\n\n%s", err, usage)
+		return 2
+	case err != nil:
+		fmt.Fprintf(stderr, "sequin: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func defaultAddr() string {
+	if addr := os.Getenv("SEQUIN_ADDR"); addr != "" {
+		return addr
+	}
+	return DefaultAddr
+}
+
+type command struct {
+	client sequinv1connect.SequinServiceClient
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (c *command) run(ctx context.Context, name string, args []string) error {
+	switch name {
+	case "serve":
+		return c.serve(ctx, args)
+	case "list":
+		return c.list(ctx, args)
+	case "start":
+		return c.start(ctx, args)
+	case "exec":
+		return c.exec(ctx, args)
+	case "get":
+		return c.get(ctx, args)
+	case "watch":
+		return c.watch(ctx, args)
+	case "cancel":
+		return c.cancel(ctx, args)
+	case "tree":
+		return c.tree(ctx, args)
+	case "ops":
+		return c.ops(ctx, args)
+	}
+	return fmt.Errorf("%w: unknown command %q", errUsage, name)
+}
+
+func (c *command) serve(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	listen := fs.String("listen", "localhost:8080", "listen `address`")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	m := metrics.New()
+	return service.ListenAndServe(ctx, *listen,
+		service.WithServer(local.NewServer(local.WithMetrics(m))),
+		service.WithMetricsHandler(m))
+}
+
+func (c *command) list(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: list takes no arguments", errUsage)
+	}
+	resp, err := c.client.ListEndpoints(ctx, connect.NewRequest(&sequinv1.ListEndpointsRequest{}))
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tARGS")
+	for _, ep := range resp.Msg.GetEndpoints() {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", ep.GetName(), ep.GetVersion(), strings.Join(ep.GetArgNames(), ", "))
+	}
+	return w.Flush()
+}
+
+func (c *command) start(ctx context.Context, args []string) error {
+	op, md, err := c.operation("start", args)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Start(ctx, connect.NewRequest(&sequinv1.StartRequest{Operation: op, Metadata: md}))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.stdout, resp.Msg.GetRequestId())
+	return nil
+}
+
+func (c *command) exec(ctx context.Context, args []string) error {
+	op, md, err := c.operation("exec", args)
+	if err != nil {
+		return err
+	}
+	stream, err := c.client.Exec(ctx, connect.NewRequest(&sequinv1.ExecRequest{Operation: op, Metadata: md}))
+	if err != nil {
+		return err
+	}
+	var last *longrunningpb.Operation
+	for stream.Receive() {
+		last = stream.Msg()
+	}
+	if err := stream.Err(); err != nil {
+		return err
+	}
+	return c.print(last)
+}
+
+func (c *command) get(ctx context.Context, args []string) error {
+	id, err := requestID("get", args)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Get(ctx, connect.NewRequest(&sequinv1.GetRequest{RequestId: id}))
+	if err != nil {
+		return err
+	}
+	return c.print(resp.Msg)
+}
+
+func (c *command) watch(ctx context.Context, args []string) error {
+	id, err := requestID("watch", args)
+	if err != nil {
+		return err
+	}
+	stream, err := c.client.Watch(ctx, connect.NewRequest(&sequinv1.WatchRequest{RequestId: id}))
+	if err != nil {
+		return err
+	}
+	for stream.Receive() {
+		if err := c.print(stream.Msg()); err != nil {
+			return err
+		}
+	}
+	return stream.Err()
+}
+
+func (c *command) cancel(ctx context.Context, args []string) error {
+	id, err := requestID("cancel", args)
+	if err != nil {
+		return err
+	}
+	_, err = c.client.Cancel(ctx, connect.NewRequest(&sequinv1.CancelRequest{RequestId: id}))
+	return err
+}
+
+func (c *command) tree(ctx context.Context, args []string) error {
+	id, err := requestID("tree", args)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.GetCallTree(ctx, connect.NewRequest(&sequinv1.GetCallTreeRequest{RequestId: id}))
+	if err != nil {
+		return err
+	}
+	c.printNode(resp.Msg.GetRoot(), "")
+	return nil
+}
+
+func (c *command) ops(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("ops", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	req := &sequinv1.ListOperationsRequest{Labels: map[string]string{}}
+	fs.StringVar(&req.Name, "endpoint", "", "endpoint `name`")
+	status := fs.String("status", "", "`status` of operations")
+	fs.Var(labelFlag(req.Labels), "label", "`key=value` label of operations, may be repeated")
+	since := fs.String("since", "", "submitted at or after `time`")
+	until := fs.String("until", "", "submitted before `time`")
+	fs.BoolVar(&req.TopLevel, "top", false, "exclude operations started by other operations")
+	limit := fs.Int("limit", 20, "maximum `number` of operations")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: ops takes no arguments", errUsage)
+	}
+	if *status != "" {
+		v, ok := sequinv1.OperationStatus_value["OPERATION_STATUS_"+strings.ToUpper(*status)]
+		if !ok || v == 0 {
+			return fmt.Errorf("%w: unknown status %q", errUsage, *status)
+		}
+		req.Status = sequinv1.OperationStatus(v)
+	}
+	var err error
+	now := time.Now()
+	if req.SubmittedAfter, err = parseTime(*since, now); err != nil {
+		return fmt.Errorf("%w: -since: %v", errUsage, err)
+	}
+	if req.SubmittedBefore, err = parseTime(*until, now); err != nil {
+		return fmt.Errorf("%w: -until: %v", errUsage, err)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "REQUEST ID\tENDPOINT\tSTATUS\tSUBMITTED")
+	for remaining := *limit; remaining > 0; {
+		req.PageSize = int32(min(remaining, 1000))
+		resp, err := c.client.ListOperations(ctx, connect.NewRequest(req))
+		if err != nil {
+			return err
+		}
+		for _, op := range resp.Msg.GetOperations() {
+			var md sequinv1.RunMetadata
+			if err := op.GetMetadata().UnmarshalTo(&md); err != nil {
+				return err
+			}
+			state := "running"
+			if op.GetDone() {
+				state = doneState(op.GetError())
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", op.GetName(), md.GetEndpoint(), state,
+				md.GetSubmittedAt().AsTime().Local().Format(time.DateTime))
+		}
+		remaining -= len(resp.Msg.GetOperations())
+		if req.PageToken = resp.Msg.GetNextPageToken(); req.PageToken == "" {
+			break
+		}
+	}
+	return w.Flush()
+}
+
+// parseTime parses an RFC 3339 time, or a duration before now.
+// Returns nil if s is empty.
+func parseTime(s string, now time.Time) (*timestamppb.Timestamp, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return timestamppb.New(now.Add(-d)), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not an RFC 3339 time or a duration", s)
+	}
+	return timestamppb.New(t), nil
+}
+
+func (c *command) printNode(n *sequinv1.CallNode, indent string) {
+	state := "running"
+	if n.GetDone() {
+		state = doneState(n.GetStatus())
+	}
+	if n.GetDetached() {
+		state += " (detached)"
+	}
+	fmt.Fprintf(c.stdout, "%s%s [%s] %s\n", indent, n.GetName(), n.GetRequestId(), state)
+	for _, child := range n.GetChildren() {
+		c.printNode(child, indent+"  ")
+	}
+}
+
+// doneState describes the status of a completed operation or call. Errors
+// without a message are described by their code.
+func doneState(st *status.Status) string {
+	c := codes.Code(st.GetCode())
+	switch {
+	case c == codes.OK:
+		return "ok"
+	case st.GetMessage() == "":
+		return "error: " + c.String()
+	}
+	return "error: " + st.GetMessage()
+}
+
+func (c *command) print(msg proto.Message) error {
+	data, err := protojson.MarshalOptions{Multiline: true}.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(c.stdout, string(data))
+	return err
+}
+
+// operation parses the labels, endpoint name and JSON arguments of an
+// operation.
+func (c *command) operation(cmd string, args []string) (*anypb.Any, *sequinv1.RequestMetadata, error) {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	labels := labelFlag{}
+	fs.Var(labels, "label", "`key=value` label of the operation, may be repeated")
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", errUsage, err)
+	}
+	args = fs.Args()
+	if len(args) < 1 || len(args) > 2 {
+		return nil, nil, fmt.Errorf("%w: %s takes an endpoint name and optional JSON arguments", errUsage, cmd)
+	}
+	op := &sequinv1.JSONOperation{Name: args[0]}
+	if len(args) == 2 {
+		op.Args = &structpb.Value{}
+		if err := protojson.Unmarshal([]byte(args[1]), op.Args); err != nil {
+			return nil, nil, fmt.Errorf("invalid JSON arguments: %w", err)
+		}
+		if err := checkNumbers([]byte(args[1])); err != nil {
+			return nil, nil, fmt.Errorf("invalid JSON arguments: %w", err)
+		}
+	}
+	a, err := anypb.New(op)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, &sequinv1.RequestMetadata{Labels: labels}, nil
+}
+
+// checkNumbers returns an error if the JSON holds a number which can't be
+// represented exactly by google.protobuf.Value, which stores numbers as
+// doubles. This rejects integers beyond 2^53 rather than rounding them.
+func checkNumbers(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			// Syntax errors are reported by protojson.
+			return nil
+		}
+		n, ok := tok.(json.Number)
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(n.String(), 64)
+		if err != nil {
+			return fmt.Errorf("number %s is out of range", n)
+		}
+		if i, ok := new(big.Int).SetString(n.String(), 10); ok {
+			if exact, _ := new(big.Float).SetFloat64(f).Int(nil); exact.Cmp(i) != 0 {
+				return fmt.Errorf("integer %s can't be represented exactly", n)
+			}
+		}
+	}
+}
+
+// labelFlag collects repeated key=value flags.
+type labelFlag map[string]string
+
+func (f labelFlag) String() string {
+	return ""
+}
+
+func (f labelFlag) Set(v string) error {
+	key, value, ok := strings.Cut(v, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("label %q is not of the form key=value", v)
+	}
+	f[key] = value
+	return nil
+}
+
+func requestID(cmd string, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("%w: %s takes a request ID", errUsage, cmd)
+	}
+	return args[0], nil
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+
+	"github.com/vgough/sequin"
+	"github.com/vgough/sequin/gen/sequin/v1/sequinv1connect"
+	"github.com/vgough/sequin/local"
+	"github.com/vgough/sequin/registry"
+	"github.com/vgough/sequin/service"
+)
+
+func newTestServer(t *testing.T) string {
+	reg := registry.New()
+	greet := sequin.RegisterIn(reg, func(_ context.Context, name string) (string, error) {
+		if name == "" {
+			return "", errors.New("name required")
+		}
+		return "hello " + name, nil
+	}, sequin.Name("cli.test.greet"), sequin.ArgNames("name"))
+	sequin.RegisterIn(reg, func(ctx context.Context, names []string) (int, error) {
+		for _, name := range names {
+			if _, err := greet(ctx, name); err != nil {
+				return 0, err
+			}
+		}
+		return len(names), nil
+	}, sequin.Name("cli.test.greet-all"))
+
+	mux := http.NewServeMux()
+	mux.Handle(sequinv1connect.NewSequinServiceHandler(service.New(local.NewServer(local.WithRegistry(reg)))))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func run(t *testing.T, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := Run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	addr := newTestServer(t)
+
+	code, out, _ := run(t, "-addr", addr, "list")
+	require.Equal(t, 0, code)
+	require.Contains(t, out, "cli.test.greet ")
+	require.Contains(t, out, "name")
+	require.Contains(t, out, "cli.test.greet-all")
+
+	code, out, _ = run(t, "-addr", addr, "exec", "cli.test.greet", `{"name": "world"}`)
+	require.Equal(t, 0, code)
+	require.Regexp(t, `"done":\s+true`, out)
+	require.Contains(t, out, `hello world`)
+
+	code, out, _ = run(t, "-addr", addr, "exec", "-label", "team=infra", "cli.test.greet", `["labels"]`)
+	require.Equal(t, 0, code)
+	require.Regexp(t, `"team":\s+"infra"`, out)
+
+	code, out, _ = run(t, "-addr", addr, "start", "cli.test.greet-all", `[["a", "", "b"]]`)
+	require.Equal(t, 0, code)
+	id := strings.TrimSpace(out)
+	require.NotEmpty(t, id)
+
+	code, out, _ = run(t, "-addr", addr, "watch", id)
+	require.Equal(t, 0, code)
+	require.Contains(t, out, "name required")
+
+	code, out, _ = run(t, "-addr", addr, "get", id)
+	require.Equal(t, 0, code)
+	require.Contains(t, out, "name required")
+
+	code, out, _ = run(t, "-addr", addr, "tree", id)
+	require.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3)
+	require.True(t, strings.HasPrefix(lines[0], "cli.test.greet-all ["+id+"] error: name required"))
+	require.True(t, strings.HasPrefix(lines[1], "  cli.test.greet ["))
+	require.True(t, strings.HasSuffix(lines[1], "] ok"))
+	require.True(t, strings.HasSuffix(lines[2], "] error: name required"))
+
+	code, out, _ = run(t, "-addr", addr, "ops", "-status", "failed", "-since", "1h")
+	require.Equal(t, 0, code)
+	lines = strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3)
+	require.True(t, strings.HasPrefix(lines[0], "REQUEST ID"))
+	require.Contains(t, out, id+"  cli.test.greet-all  error: name required")
+
+	code, out, _ = run(t, "-addr", addr, "ops", "-top", "-label", "team=infra")
+	require.Equal(t, 0, code)
+	lines = strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[1], "cli.test.greet  ok")
+
+	code, _, errOut := run(t, "-addr", addr, "cancel", id)
+	require.Equal(t, 1, code)
+	require.Contains(t, errOut, "already done")
+}
+
+func TestRun_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"unknown"},
+		{"get"},
+		{"start"},
+		{"list", "extra"},
+		{"ops", "-status", "unknown"},
+		{"ops", "-since", "yesterday"},
+		{"start", "-label", "invalid", "cli.test.greet"},
+	} {
+		code, _, errOut := run(t, args...)
+		require.Equal(t, 2, code, args)
+		require.Contains(t, errOut, "usage: sequin")
+	}
+
+	code, _, errOut := run(t, "start", "cli.test.greet", "{invalid")
+	require.Equal(t, 1, code)
+	require.Contains(t, errOut, "invalid JSON arguments")
+
+	code, _, errOut = run(t, "start", "cli.test.greet", `[{"id": 9007199254740993}]`)
+	require.Equal(t, 1, code)
+	require.Contains(t, errOut, "integer 9007199254740993 can't be represented exactly")
+}
+
+func TestCheckNumbers(t *testing.T) {
+	for _, ok := range []string{`[1, -2.5, 1e3, 9007199254740992, 1152921504606846976]`, `{"a": [0.1]}`} {
+		require.NoError(t, checkNumbers([]byte(ok)), ok)
+	}
+	for _, bad := range []string{`[9007199254740993]`, `{"a": -9007199254740993}`, `[1e400]`} {
+		require.Error(t, checkNumbers([]byte(bad)), bad)
+	}
+}
+
+func TestDoneState(t *testing.T) {
+	require.Equal(t, "ok", doneState(nil))
+	require.Equal(t, "ok", doneState(&status.Status{}))
+	require.Equal(t, "error: failed", doneState(&status.Status{Code: int32(codes.Unknown), Message: "failed"}))
+	require.Equal(t, "error: Unknown", doneState(&status.Status{Code: int32(codes.Unknown)}))
+}
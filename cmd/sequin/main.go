@@ -0,0 +1,8 @@
+// Command sequin operates a sequin deployment. See package cli for details.
+package main
+
+import "github.com/vgough/sequin/cli"
+
+func main() {
+	cli.Main()
+}
@@ -0,0 +1,55 @@
+package sequin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vgough/sequin/internal"
+	"github.com/vgough/sequin/registry"
+)
+
+// Compensate sets the name of an endpoint which undoes the effects of the
+// endpoint, for use in sagas.
+//
+// When a call of the endpoint made by an execution completes without an
+// error, the runtime records the compensating call. If the execution then
+// fails or is cancelled, the recorded compensating calls are made in the
+// reverse order in which their steps completed, before the failure is stored.
+// Compensating calls are stored like any other call, so a failed execution
+// which is resumed only repeats those which had not completed.
+//
+// The compensating endpoint must take the same arguments as the endpoint,
+// and is called with the arguments of the completed call. Its results other
+// than the error are ignored. Detached calls are not compensated.
+func Compensate(name string) RegisterOpt {
+	return func(ep *registry.Endpoint) error {
+		if name == "" {
+			return errors.New("compensating endpoint name cannot be empty")
+		}
+		ep.Metadata[internal.Compensation] = name
+		return nil
+	}
+}
+
+var compensationMD internal.MDKey[string]
+
+// WithCompensation returns a context for making calls which are compensated
+// by the named endpoint, replacing any compensation set by Compensate when
+// the endpoint was registered. An empty name disables compensation of the
+// calls. See Compensate.
+//
+//	ctx := sequin.WithCompensation(ctx, "billing.refund")
+//	receipt, err := charge(ctx, order)
+func WithCompensation(ctx context.Context, name string) context.Context {
+	return compensationMD.Set(ctx, name)
+}
+
+// Compensation returns the name of the endpoint which compensates a call of
+// the endpoint made with the context, or an empty string if there is none.
+func Compensation(ctx context.Context, ep *registry.Endpoint) string {
+	if name, ok := compensationMD.Lookup(ctx); ok {
+		return name
+	}
+	name, _ := ep.Metadata[internal.Compensation].(string)
+	return name
+}
@@ -0,0 +1,61 @@
+package sequin
+
+import (
+	"context"
+
+	"github.com/vgough/sequin/internal"
+)
+
+// ParentClosePolicy determines what happens to a detached call when the
+// execution which made it completes. See Detach.
+type ParentClosePolicy int
+
+const (
+	// ParentCloseAbandon leaves the call running after the execution which
+	// made it completes or is cancelled.
+	ParentCloseAbandon ParentClosePolicy = iota
+	// ParentCloseCancel cancels the call when the execution which made it
+	// completes or is cancelled.
+	ParentCloseCancel
+	// ParentCloseWait delays completion of the execution which made the call
+	// until the call completes. The call is not cancelled along with the
+	// execution.
+	ParentCloseWait
+)
+
+func (p ParentClosePolicy) String() string {
+	switch p {
+	case ParentCloseAbandon:
+		return "abandon"
+	case ParentCloseCancel:
+		return "cancel"
+	case ParentCloseWait:
+		return "wait"
+	}
+	return "unknown"
+}
+
+var detachMD internal.MDKey[ParentClosePolicy]
+
+// Detach returns a context for making a detached call, which starts a request
+// with a lifetime independent of the execution making the call.
+//
+// The request has a top-level request ID, as if made outside of any
+// execution, and the runtime records the execution which made the call as its
+// originator. The policy determines what happens to the request when the
+// execution completes.
+//
+// The context should be used for a single call. Detached calls are typically
+// started with Async, so the execution can continue while they run:
+//
+//	f := sequin.Async(sequin.Detach(ctx, sequin.ParentCloseAbandon),
+//		func(ctx context.Context) (string, error) { return provision(ctx, spec) })
+func Detach(ctx context.Context, policy ParentClosePolicy) context.Context {
+	return detachMD.Set(ctx, policy)
+}
+
+// DetachPolicy returns the policy of a context returned by Detach, and
+// whether calls made with the context are detached.
+func DetachPolicy(ctx context.Context) (ParentClosePolicy, bool) {
+	return detachMD.Lookup(ctx)
+}
@@ -0,0 +1,140 @@
+package sequin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultParallelism is the number of calls made concurrently by Map and All,
+// unless set by Parallelism.
+const DefaultParallelism = 10
+
+// Sequencer is implemented by runtimes which record the order in which an
+// execution makes calls. Map and All wait for each call to be recorded before
+// making the next, so that concurrent calls are recorded in a deterministic
+// order.
+type Sequencer interface {
+	// OnIssued returns a context for making a call, such that issued is
+	// called with the ID of the request once the call has been recorded, or
+	// with an empty ID if the call failed first.
+	OnIssued(ctx context.Context, issued func(requestID string)) context.Context
+}
+
+// FanOutOpt is an option for Map and All.
+type FanOutOpt func(*fanOut)
+
+type fanOut struct {
+	parallelism int
+}
+
+// Parallelism sets the maximum number of calls made concurrently.
+// The default is DefaultParallelism.
+func Parallelism(n int) FanOutOpt {
+	return func(f *fanOut) {
+		f.parallelism = max(n, 1)
+	}
+}
+
+// FanOutError reports the calls which failed in Map or All.
+type FanOutError struct {
+	Errs []error // Error of each call, by index, nil for calls which succeeded.
+}
+
+func (e *FanOutError) Error() string {
+	var failed []string
+	for i, err := range e.Errs {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("call %d: %v", i, err))
+		}
+	}
+	return fmt.Sprintf("%d of %d calls failed: %s", len(failed), len(e.Errs), strings.Join(failed, "; "))
+}
+
+// Unwrap returns the errors of the calls which failed.
+func (e *FanOutError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Map calls fn with each input, making calls concurrently, and returns the
+// results in the order of the inputs.
+//
+// fn should make a single call through the runtime, such as a function
+// returned by Register, possibly adapting its arguments. The calls are
+// recorded in the order of the inputs, and each call's outcome is stored by
+// the runtime, so an execution which is resumed only repeats calls which had
+// not completed.
+//
+// Every call is made, even if some fail. If any fail, the error is a
+// *FanOutError, and the results of failed calls are zero values.
+//
+//	sizes, err := sequin.Map(ctx, urls, fetchSize, sequin.Parallelism(4))
+func Map[In, Out any](ctx context.Context, inputs []In, fn func(context.Context, In) (Out, error),
+	opts ...FanOutOpt) ([]Out, error) {
+	out := make([]Out, len(inputs))
+	fns := make([]func(context.Context) error, len(inputs))
+	for i, in := range inputs {
+		fns[i] = func(ctx context.Context) error {
+			var err error
+			out[i], err = fn(ctx, in)
+			return err
+		}
+	}
+	return out, All(ctx, fns, opts...)
+}
+
+// All calls each of the functions concurrently, and waits for them to
+// return. See Map for how calls are made and errors are reported.
+func All(ctx context.Context, fns []func(context.Context) error, opts ...FanOutOpt) error {
+	f := fanOut{parallelism: DefaultParallelism}
+	for _, opt := range opts {
+		opt(&f)
+	}
+	seq, _ := GetRuntime(ctx).(Sequencer)
+
+	errs := make([]error, len(fns))
+	sem := make(chan struct{}, f.parallelism)
+	var wg sync.WaitGroup
+	for i, fn := range fns {
+		sem <- struct{}{}
+		done := make(chan struct{})
+		issued := make(chan struct{})
+		callCtx := ctx
+		if seq != nil {
+			var once sync.Once
+			callCtx = seq.OnIssued(ctx, func(string) { once.Do(func() { close(issued) }) })
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				close(done)
+				wg.Done()
+			}()
+			errs[i] = fn(callCtx)
+		}()
+		if seq != nil {
+			// Calls are made in order, but may complete in any order. The
+			// call completes without being issued if fn made no call.
+			select {
+			case <-issued:
+			case <-done:
+			}
+		}
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return &FanOutError{Errs: errs}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package sequin
+
+import (
+	"context"
+	"sync"
+)
+
+// Canceler is implemented by runtimes which can cancel the execution of a
+// request.
+type Canceler interface {
+	Cancel(requestID string) error
+}
+
+// Future is the result of a call started by Async, which may not have
+// completed yet.
+type Future[T any] struct {
+	rt     Runtime
+	id     string
+	done   chan struct{}
+	cancel context.CancelFunc
+
+	val T
+	err error
+}
+
+// Async starts a call in the background, and returns a future for its result.
+//
+// fn should make a single call through the runtime, such as a function
+// returned by Register, possibly adapting its arguments. Async returns once
+// the call has been recorded by the runtime, so calls started in turn are
+// recorded in that order, even though they execute concurrently. When an
+// execution is resumed, futures of calls which had completed resolve with
+// their stored outcomes, and calls which had not completed are made again.
+//
+// Runtimes which record calls wait for the calls issued by an execution to
+// complete before completing the execution, including those of futures which
+// were never waited on.
+//
+//	a := sequin.Async(ctx, func(ctx context.Context) (int, error) { return fetchSize(ctx, urlA) })
+//	b := sequin.Async(ctx, func(ctx context.Context) (int, error) { return fetchSize(ctx, urlB) })
+//	sizeA, errA := a.Wait(ctx)
+//	sizeB, errB := b.Wait(ctx)
+func Async[T any](ctx context.Context, fn func(context.Context) (T, error)) *Future[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	f := &Future[T]{
+		rt:     GetRuntime(ctx),
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	seq, ok := f.rt.(Sequencer)
+	if !ok {
+		go f.run(ctx, fn)
+		return f
+	}
+
+	issued := make(chan struct{})
+	var once sync.Once
+	callCtx := seq.OnIssued(ctx, func(requestID string) {
+		once.Do(func() {
+			f.id = requestID
+			close(issued)
+		})
+	})
+	go f.run(callCtx, fn)
+	// The call completes without being issued if fn made no call.
+	select {
+	case <-issued:
+	case <-f.done:
+	}
+	return f
+}
+
+func (f *Future[T]) run(ctx context.Context, fn func(context.Context) (T, error)) {
+	defer close(f.done)
+	defer f.cancel()
+	f.val, f.err = fn(ctx)
+}
+
+// RequestID returns the ID of the request made by the call, or an empty
+// string if the runtime doesn't record calls.
+func (f *Future[T]) RequestID() string {
+	return f.id
+}
+
+// Done returns a channel which is closed once the call has completed.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits for the call to complete and returns its results. If the
+// context is done first, the context's error is returned, and the call
+// continues.
+func (f *Future[T]) Wait(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Cancel cancels the call. If the runtime implements Canceler, the execution
+// of the request is cancelled as well, and its outcome is stored as for any
+// other cancelled request. Cancelling a call which has completed has no
+// effect.
+func (f *Future[T]) Cancel() {
+	f.cancel()
+	if c, ok := f.rt.(Canceler); ok && f.id != "" {
+		// The request may have completed, or not started executing.
+		_ = c.Cancel(f.id)
+	}
+}
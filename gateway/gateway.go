@@ -0,0 +1,274 @@
+// Package gateway exposes registered endpoints over HTTP using JSON.
+//
+// Each endpoint is served at a path derived from its name, so the endpoint
+// "billing.charge" is called with:
+//
+//	POST /billing.charge
+//	["cust-1", 100]
+//
+// Arguments are passed as a JSON array in the order of the function's
+// arguments, excluding context and injected arguments. Endpoints registered
+// with sequin.ArgNames also accept a JSON object of arguments by name.
+// Protobuf message arguments and results use the protobuf JSON mapping.
+//
+// Responses are a JSON Response. When the handler has a Tracker, which is the
+// default for local.Server, the response includes the request ID, and the
+// request can be looked up later with:
+//
+//	GET /_requests/{id}
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/vgough/sequin"
+	"github.com/vgough/sequin/internal"
+	"github.com/vgough/sequin/local"
+	"github.com/vgough/sequin/registry"
+)
+
+// RequestsPath is the path prefix used to look up requests by ID.
+// Endpoint names must start with a letter, so this never matches an endpoint.
+const RequestsPath = "/_requests/"
+
+// MaxBodySize is the default limit on the size of request bodies.
+const MaxBodySize = 1 << 20
+
+// Tracker identifies the requests made by a runtime, and reports their
+// status.
+type Tracker interface {
+	// RequestID returns the ID of the request made by calling the endpoint
+	// with the given arguments.
+	RequestID(ep *registry.Endpoint, args []reflect.Value) (string, error)
+	// Status returns the status of a request, or nil if the request is not
+	// found.
+	Status(ctx context.Context, requestID string) (*Status, error)
+}
+
+// Status is the state of a request reported by a Tracker.
+type Status struct {
+	ID   string
+	Name string // Endpoint name.
+	Done bool   // Set once the request has completed.
+	// Results holds the values returned by the endpoint, including the
+	// error, set once Done.
+	Results []reflect.Value
+}
+
+// LocalTracker returns a Tracker for requests executed by a local.Server.
+func LocalTracker(s *local.Server) Tracker {
+	return localTracker{s}
+}
+
+type localTracker struct {
+	*local.Server
+}
+
+func (t localTracker) Status(ctx context.Context, requestID string) (*Status, error) {
+	req, err := t.Lookup(ctx, requestID)
+	if err != nil || req == nil {
+		return nil, err
+	}
+	st := &Status{ID: req.ID, Name: req.Name, Done: req.Done}
+	if !req.Done {
+		return st, nil
+	}
+	ep := t.Registry().GetEndpoint(req.Name)
+	if ep == nil {
+		return nil, fmt.Errorf("%w: %s", registry.ErrEndpointNotFound, req.Name)
+	}
+	st.Results, err = internal.DecodeValues(req.Results, ep.OutputTypes)
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// Response is the JSON body returned for calls and request lookups.
+type Response struct {
+	RequestID string `json:"requestId,omitempty"`
+	Name      string `json:"name"`
+	// Done is false for requests which have not completed.
+	Done bool `json:"done"`
+	// Results holds the results other than the error, in order.
+	Results []json.RawMessage `json:"results,omitempty"`
+	// Error is the message of the error returned by the endpoint, or of the
+	// failure to call it.
+	Error string `json:"error,omitempty"`
+}
+
+// Handler is an http.Handler which calls endpoints using a runtime.
+type Handler struct {
+	runtime     sequin.Runtime
+	registry    *registry.Registry
+	tracker     Tracker
+	maxBodySize int64
+}
+
+var _ http.Handler = &Handler{}
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithRegistry sets the registry used to resolve endpoints by name.
+// The default is registry.Default.
+func WithRegistry(r *registry.Registry) Option {
+	return func(h *Handler) {
+		h.registry = r
+	}
+}
+
+// WithTracker sets the Tracker used to identify requests and look them up.
+// The default is LocalTracker if the runtime is a local.Server, and no
+// tracking otherwise.
+func WithTracker(tr Tracker) Option {
+	return func(h *Handler) {
+		h.tracker = tr
+	}
+}
+
+// WithMaxBodySize sets the limit on the size of request bodies.
+// The default is MaxBodySize.
+func WithMaxBodySize(n int64) Option {
+	return func(h *Handler) {
+		h.maxBodySize = n
+	}
+}
+
+// New returns a Handler which executes calls using the runtime.
+func New(rt sequin.Runtime, opts ...Option) *Handler {
+	h := &Handler{runtime: rt, maxBodySize: MaxBodySize}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.registry == nil {
+		h.registry = registry.Default
+	}
+	if s, ok := rt.(*local.Server); ok && h.tracker == nil {
+		h.tracker = LocalTracker(s)
+	}
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if id, ok := strings.CutPrefix(r.URL.Path, RequestsPath); ok {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeError(w, http.StatusMethodNotAllowed, "", errors.New("method not allowed"))
+			return
+		}
+		h.lookup(w, r, id)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "", errors.New("method not allowed"))
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	ep := h.registry.GetEndpoint(name)
+	if ep == nil {
+		writeError(w, http.StatusNotFound, name, fmt.Errorf("%w: %s", registry.ErrEndpointNotFound, name))
+		return
+	}
+	h.call(w, r, ep)
+}
+
+func (h *Handler) call(w http.ResponseWriter, r *http.Request, ep *registry.Endpoint) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodySize))
+	if err != nil {
+		code := http.StatusBadRequest
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			code = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, code, ep.Name, err)
+		return
+	}
+	args, err := ep.UnmarshalArgs(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ep.Name, err)
+		return
+	}
+	ep.SetContext(sequin.WithRuntime(r.Context(), h.runtime), args)
+
+	resp := Response{Name: ep.Name, Done: true}
+	if h.tracker != nil {
+		resp.RequestID, err = h.tracker.RequestID(ep, args)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, ep.Name, err)
+			return
+		}
+	}
+
+	out := h.runtime.Exec(ep, args)
+	if err := ep.GetError(out); err != nil {
+		resp.Error = err.Error()
+		writeJSON(w, http.StatusInternalServerError, resp)
+		return
+	}
+	resp.Results, err = ep.MarshalResults(out)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ep.Name, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) lookup(w http.ResponseWriter, r *http.Request, id string) {
+	if h.tracker == nil {
+		writeError(w, http.StatusNotImplemented, "", errors.New("runtime does not track requests"))
+		return
+	}
+	req, err := h.tracker.Status(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err)
+		return
+	} else if req == nil {
+		writeError(w, http.StatusNotFound, "", fmt.Errorf("request %s not found", id))
+		return
+	}
+
+	resp := Response{RequestID: req.ID, Name: req.Name, Done: req.Done}
+	if !req.Done {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	ep := h.registry.GetEndpoint(req.Name)
+	if ep == nil {
+		writeError(w, http.StatusInternalServerError, req.Name,
+			fmt.Errorf("%w: %s", registry.ErrEndpointNotFound, req.Name))
+		return
+	}
+	out := req.Results
+	if len(out) != len(ep.OutputTypes) {
+		writeError(w, http.StatusInternalServerError, req.Name,
+			fmt.Errorf("%s returns %d results, got %d", req.Name, len(ep.OutputTypes), len(out)))
+		return
+	}
+	if err := ep.GetError(out); err != nil {
+		resp.Error = err.Error()
+	} else if resp.Results, err = ep.MarshalResults(out); err != nil {
+		writeError(w, http.StatusInternalServerError, req.Name, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func writeError(w http.ResponseWriter, code int, name string, err error) {
+	writeJSON(w, code, Response{Name: name, Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,192 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/vgough/sequin"
+	"github.com/vgough/sequin/local"
+	"github.com/vgough/sequin/registry"
+)
+
+type item struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"qty"`
+}
+
+func newTestHandler(t *testing.T) *httptest.Server {
+	reg := registry.New()
+	sequin.RegisterIn(reg, func(_ context.Context, items []item, discount int) (int, error) {
+		if discount < 0 {
+			return 0, errors.New("negative discount")
+		}
+		var total int
+		for _, it := range items {
+			total += it.Quantity * 10
+		}
+		return total - discount, nil
+	}, sequin.Name("gateway.test.total"), sequin.ArgNames("items", "discount"))
+	sequin.RegisterIn(reg, func(_ context.Context, a, b string) (string, error) {
+		return a + b, nil
+	}, sequin.Name("gateway.test.concat"))
+	sequin.RegisterIn(reg, func(_ context.Context, s *wrapperspb.StringValue) (*wrapperspb.Int64Value, error) {
+		return wrapperspb.Int64(int64(len(s.GetValue()))), nil
+	}, sequin.Name("gateway.test.length"))
+
+	srv := httptest.NewServer(New(local.NewServer(local.WithRegistry(reg)), WithRegistry(reg)))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func post(t *testing.T, srv *httptest.Server, path, body string) (int, Response) {
+	res, err := http.Post(srv.URL+path, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	return decodeResponse(t, res)
+}
+
+func get(t *testing.T, srv *httptest.Server, path string) (int, Response) {
+	res, err := http.Get(srv.URL + path)
+	require.NoError(t, err)
+	return decodeResponse(t, res)
+}
+
+func decodeResponse(t *testing.T, res *http.Response) (int, Response) {
+	defer res.Body.Close()
+	require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	var resp Response
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+	return res.StatusCode, resp
+}
+
+func TestHandler(t *testing.T) {
+	srv := newTestHandler(t)
+
+	code, resp := post(t, srv, "/gateway.test.total", `[[{"sku":"a","qty":2},{"sku":"b","qty":1}], 5]`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "gateway.test.total", resp.Name)
+	require.True(t, resp.Done)
+	require.NotEmpty(t, resp.RequestID)
+	require.Len(t, resp.Results, 1)
+	require.JSONEq(t, "25", string(resp.Results[0]))
+
+	// Named arguments identify the same request.
+	code, named := post(t, srv, "/gateway.test.total", `{"discount":5, "items":[{"sku":"a","qty":2},{"sku":"b","qty":1}]}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, resp, named)
+
+	// The request can be looked up by ID.
+	code, status := get(t, srv, RequestsPath+url.PathEscape(resp.RequestID))
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, resp, status)
+
+	code, resp = post(t, srv, "/gateway.test.concat", `["a", "b"]`)
+	require.Equal(t, http.StatusOK, code)
+	require.JSONEq(t, `"ab"`, string(resp.Results[0]))
+
+	// Wrapper messages use the protobuf JSON mapping of their value.
+	code, resp = post(t, srv, "/gateway.test.length", `["hello"]`)
+	require.Equal(t, http.StatusOK, code)
+	require.JSONEq(t, `"5"`, string(resp.Results[0]))
+}
+
+func TestHandler_Errors(t *testing.T) {
+	srv := newTestHandler(t)
+
+	code, resp := post(t, srv, "/gateway.test.total", `[[], -1]`)
+	require.Equal(t, http.StatusInternalServerError, code)
+	require.True(t, resp.Done)
+	require.Equal(t, "negative discount", resp.Error)
+	code, status := get(t, srv, RequestsPath+url.PathEscape(resp.RequestID))
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, resp, status)
+
+	tests := []struct {
+		path, body string
+		code       int
+		err        string
+	}{
+		{"/gateway.test.unknown", `[]`, http.StatusNotFound, "endpoint not found"},
+		{"/gateway.test.total", `[[]]`, http.StatusBadRequest, "expects 2 arguments, got 1"},
+		{"/gateway.test.total", ``, http.StatusBadRequest, "expects 2 arguments, got 0"},
+		{"/gateway.test.total", `[[], "x"]`, http.StatusBadRequest, "argument 1"},
+		{"/gateway.test.total", `{"count": 1}`, http.StatusBadRequest, `no argument named "count"`},
+		{"/gateway.test.concat", `{"a": "x"}`, http.StatusBadRequest, "does not have argument names"},
+		{"/gateway.test.total", `"x"`, http.StatusBadRequest, "must be a JSON array or object"},
+	}
+	for _, tc := range tests {
+		code, resp := post(t, srv, tc.path, tc.body)
+		require.Equal(t, tc.code, code, tc.body)
+		require.False(t, resp.Done)
+		require.Contains(t, resp.Error, tc.err)
+	}
+
+	code, resp = get(t, srv, "/gateway.test.total")
+	require.Equal(t, http.StatusMethodNotAllowed, code)
+	require.NotEmpty(t, resp.Error)
+
+	code, _ = get(t, srv, RequestsPath+"missing")
+	require.Equal(t, http.StatusNotFound, code)
+}
+
+func TestHandler_Body(t *testing.T) {
+	reg := registry.New()
+	sequin.RegisterIn(reg, func(_ context.Context, s string) (int, error) {
+		return len(s), nil
+	}, sequin.Name("gateway.test.len"))
+	h := New(local.NewServer(local.WithRegistry(reg)), WithRegistry(reg), WithMaxBodySize(16))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/gateway.test.len", strings.NewReader(`["0123456789abcdef"]`)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/gateway.test.len", iotest.ErrReader(errors.New("connection reset"))))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "connection reset")
+}
+
+// fakeTracker reports a single completed request.
+type fakeTracker struct{}
+
+func (fakeTracker) RequestID(*registry.Endpoint, []reflect.Value) (string, error) {
+	return "req-1", nil
+}
+
+func (fakeTracker) Status(_ context.Context, id string) (*Status, error) {
+	if id != "req-1" {
+		return nil, nil
+	}
+	var err error
+	return &Status{ID: id, Name: "gateway.test.len", Done: true,
+		Results: []reflect.Value{reflect.ValueOf(3), reflect.ValueOf(&err).Elem()}}, nil
+}
+
+func TestHandler_Tracker(t *testing.T) {
+	reg := registry.New()
+	sequin.RegisterIn(reg, func(_ context.Context, s string) (int, error) {
+		return len(s), nil
+	}, sequin.Name("gateway.test.len"))
+	srv := httptest.NewServer(New(local.NewServer(local.WithRegistry(reg)),
+		WithRegistry(reg), WithTracker(fakeTracker{})))
+	t.Cleanup(srv.Close)
+
+	code, resp := post(t, srv, "/gateway.test.len", `["abc"]`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "req-1", resp.RequestID)
+
+	code, resp = get(t, srv, RequestsPath+"req-1")
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, resp.Done)
+	require.Equal(t, []json.RawMessage{json.RawMessage("3")}, resp.Results)
+}
@@ -375,6 +375,8 @@ func (m *StartResponse) validate(all bool) error {
 
 	var errors []error
 
+	// no validation rules for RequestId
+
 	if len(errors) > 0 {
 		return StartResponseMultiError(errors)
 	}
@@ -856,6 +858,137 @@ var _ interface {
 	ErrorName() string
 } = FuncOperationValidationError{}
 
+// Validate checks the field values on JSONOperation with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *JSONOperation) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on JSONOperation with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in JSONOperationMultiError, or
+// nil if none found.
+func (m *JSONOperation) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *JSONOperation) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Name
+
+	if all {
+		switch v := interface{}(m.GetArgs()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, JSONOperationValidationError{
+					field:  "Args",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, JSONOperationValidationError{
+					field:  "Args",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetArgs()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return JSONOperationValidationError{
+				field:  "Args",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return JSONOperationMultiError(errors)
+	}
+
+	return nil
+}
+
+// JSONOperationMultiError is an error wrapping multiple validation errors
+// returned by JSONOperation.ValidateAll() if the designated constraints
+// aren't met.
+type JSONOperationMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m JSONOperationMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m JSONOperationMultiError) AllErrors() []error { return m }
+
+// JSONOperationValidationError is the validation error returned by
+// JSONOperation.Validate if the designated constraints aren't met.
+type JSONOperationValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e JSONOperationValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e JSONOperationValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e JSONOperationValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e JSONOperationValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e JSONOperationValidationError) ErrorName() string { return "JSONOperationValidationError" }
+
+// Error satisfies the builtin error interface
+func (e JSONOperationValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sJSONOperation.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = JSONOperationValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = JSONOperationValidationError{}
+
 // Validate checks the field values on ExecResponse with the rules defined in
 // the proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
@@ -1266,6 +1399,44 @@ func (m *RunMetadata) validate(all bool) error {
 		}
 	}
 
+	// no validation rules for Endpoint
+
+	// no validation rules for Originator
+
+	for idx, item := range m.GetCompensations() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, RunMetadataValidationError{
+						field:  fmt.Sprintf("Compensations[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, RunMetadataValidationError{
+						field:  fmt.Sprintf("Compensations[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return RunMetadataValidationError{
+					field:  fmt.Sprintf("Compensations[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
 	if len(errors) > 0 {
 		return RunMetadataMultiError(errors)
 	}
@@ -1342,3 +1513,1556 @@ var _ interface {
 	Cause() error
 	ErrorName() string
 } = RunMetadataValidationError{}
+
+// Validate checks the field values on Compensation with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Compensation) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Compensation with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in CompensationMultiError, or
+// nil if none found.
+func (m *Compensation) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Compensation) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Step
+
+	// no validation rules for Endpoint
+
+	// no validation rules for RequestId
+
+	// no validation rules for Done
+
+	if all {
+		switch v := interface{}(m.GetStatus()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, CompensationValidationError{
+					field:  "Status",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, CompensationValidationError{
+					field:  "Status",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetStatus()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return CompensationValidationError{
+				field:  "Status",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return CompensationMultiError(errors)
+	}
+
+	return nil
+}
+
+// CompensationMultiError is an error wrapping multiple validation errors
+// returned by Compensation.ValidateAll() if the designated constraints aren't met.
+type CompensationMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CompensationMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CompensationMultiError) AllErrors() []error { return m }
+
+// CompensationValidationError is the validation error returned by
+// Compensation.Validate if the designated constraints aren't met.
+type CompensationValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CompensationValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CompensationValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CompensationValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CompensationValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CompensationValidationError) ErrorName() string { return "CompensationValidationError" }
+
+// Error satisfies the builtin error interface
+func (e CompensationValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCompensation.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CompensationValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CompensationValidationError{}
+
+// Validate checks the field values on WatchRequest with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *WatchRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on WatchRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in WatchRequestMultiError, or
+// nil if none found.
+func (m *WatchRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *WatchRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for RequestId
+
+	if len(errors) > 0 {
+		return WatchRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// WatchRequestMultiError is an error wrapping multiple validation errors
+// returned by WatchRequest.ValidateAll() if the designated constraints aren't met.
+type WatchRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m WatchRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m WatchRequestMultiError) AllErrors() []error { return m }
+
+// WatchRequestValidationError is the validation error returned by
+// WatchRequest.Validate if the designated constraints aren't met.
+type WatchRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e WatchRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e WatchRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e WatchRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e WatchRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e WatchRequestValidationError) ErrorName() string { return "WatchRequestValidationError" }
+
+// Error satisfies the builtin error interface
+func (e WatchRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sWatchRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = WatchRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = WatchRequestValidationError{}
+
+// Validate checks the field values on CancelRequest with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *CancelRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on CancelRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in CancelRequestMultiError, or
+// nil if none found.
+func (m *CancelRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *CancelRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for RequestId
+
+	if len(errors) > 0 {
+		return CancelRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// CancelRequestMultiError is an error wrapping multiple validation errors
+// returned by CancelRequest.ValidateAll() if the designated constraints
+// aren't met.
+type CancelRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CancelRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CancelRequestMultiError) AllErrors() []error { return m }
+
+// CancelRequestValidationError is the validation error returned by
+// CancelRequest.Validate if the designated constraints aren't met.
+type CancelRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CancelRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CancelRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CancelRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CancelRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CancelRequestValidationError) ErrorName() string { return "CancelRequestValidationError" }
+
+// Error satisfies the builtin error interface
+func (e CancelRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCancelRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CancelRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CancelRequestValidationError{}
+
+// Validate checks the field values on CancelResponse with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *CancelResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on CancelResponse with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in CancelResponseMultiError,
+// or nil if none found.
+func (m *CancelResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *CancelResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if len(errors) > 0 {
+		return CancelResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// CancelResponseMultiError is an error wrapping multiple validation errors
+// returned by CancelResponse.ValidateAll() if the designated constraints
+// aren't met.
+type CancelResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CancelResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CancelResponseMultiError) AllErrors() []error { return m }
+
+// CancelResponseValidationError is the validation error returned by
+// CancelResponse.Validate if the designated constraints aren't met.
+type CancelResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CancelResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CancelResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CancelResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CancelResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CancelResponseValidationError) ErrorName() string { return "CancelResponseValidationError" }
+
+// Error satisfies the builtin error interface
+func (e CancelResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCancelResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CancelResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CancelResponseValidationError{}
+
+// Validate checks the field values on ListEndpointsRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ListEndpointsRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ListEndpointsRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ListEndpointsRequestMultiError, or nil if none found.
+func (m *ListEndpointsRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ListEndpointsRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if len(errors) > 0 {
+		return ListEndpointsRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// ListEndpointsRequestMultiError is an error wrapping multiple validation
+// errors returned by ListEndpointsRequest.ValidateAll() if the designated
+// constraints aren't met.
+type ListEndpointsRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ListEndpointsRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ListEndpointsRequestMultiError) AllErrors() []error { return m }
+
+// ListEndpointsRequestValidationError is the validation error returned by
+// ListEndpointsRequest.Validate if the designated constraints aren't met.
+type ListEndpointsRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ListEndpointsRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ListEndpointsRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ListEndpointsRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ListEndpointsRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ListEndpointsRequestValidationError) ErrorName() string {
+	return "ListEndpointsRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ListEndpointsRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sListEndpointsRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ListEndpointsRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ListEndpointsRequestValidationError{}
+
+// Validate checks the field values on ListEndpointsResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ListEndpointsResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ListEndpointsResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ListEndpointsResponseMultiError, or nil if none found.
+func (m *ListEndpointsResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ListEndpointsResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	for idx, item := range m.GetEndpoints() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, ListEndpointsResponseValidationError{
+						field:  fmt.Sprintf("Endpoints[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, ListEndpointsResponseValidationError{
+						field:  fmt.Sprintf("Endpoints[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return ListEndpointsResponseValidationError{
+					field:  fmt.Sprintf("Endpoints[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return ListEndpointsResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// ListEndpointsResponseMultiError is an error wrapping multiple validation
+// errors returned by ListEndpointsResponse.ValidateAll() if the designated
+// constraints aren't met.
+type ListEndpointsResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ListEndpointsResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ListEndpointsResponseMultiError) AllErrors() []error { return m }
+
+// ListEndpointsResponseValidationError is the validation error returned by
+// ListEndpointsResponse.Validate if the designated constraints aren't met.
+type ListEndpointsResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ListEndpointsResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ListEndpointsResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ListEndpointsResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ListEndpointsResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ListEndpointsResponseValidationError) ErrorName() string {
+	return "ListEndpointsResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ListEndpointsResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sListEndpointsResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ListEndpointsResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ListEndpointsResponseValidationError{}
+
+// Validate checks the field values on Endpoint with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Endpoint) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Endpoint with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in EndpointMultiError, or nil
+// if none found.
+func (m *Endpoint) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Endpoint) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Name
+
+	// no validation rules for Version
+
+	if all {
+		switch v := interface{}(m.GetArgsSchema()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, EndpointValidationError{
+					field:  "ArgsSchema",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, EndpointValidationError{
+					field:  "ArgsSchema",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetArgsSchema()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return EndpointValidationError{
+				field:  "ArgsSchema",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetResultsSchema()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, EndpointValidationError{
+					field:  "ResultsSchema",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, EndpointValidationError{
+					field:  "ResultsSchema",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetResultsSchema()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return EndpointValidationError{
+				field:  "ResultsSchema",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return EndpointMultiError(errors)
+	}
+
+	return nil
+}
+
+// EndpointMultiError is an error wrapping multiple validation errors returned
+// by Endpoint.ValidateAll() if the designated constraints aren't met.
+type EndpointMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m EndpointMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m EndpointMultiError) AllErrors() []error { return m }
+
+// EndpointValidationError is the validation error returned by
+// Endpoint.Validate if the designated constraints aren't met.
+type EndpointValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e EndpointValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e EndpointValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e EndpointValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e EndpointValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e EndpointValidationError) ErrorName() string { return "EndpointValidationError" }
+
+// Error satisfies the builtin error interface
+func (e EndpointValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sEndpoint.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = EndpointValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = EndpointValidationError{}
+
+// Validate checks the field values on GetCallTreeRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *GetCallTreeRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on GetCallTreeRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// GetCallTreeRequestMultiError, or nil if none found.
+func (m *GetCallTreeRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *GetCallTreeRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for RequestId
+
+	if len(errors) > 0 {
+		return GetCallTreeRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// GetCallTreeRequestMultiError is an error wrapping multiple validation errors
+// returned by GetCallTreeRequest.ValidateAll() if the designated constraints
+// aren't met.
+type GetCallTreeRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GetCallTreeRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GetCallTreeRequestMultiError) AllErrors() []error { return m }
+
+// GetCallTreeRequestValidationError is the validation error returned by
+// GetCallTreeRequest.Validate if the designated constraints aren't met.
+type GetCallTreeRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GetCallTreeRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GetCallTreeRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GetCallTreeRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GetCallTreeRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GetCallTreeRequestValidationError) ErrorName() string {
+	return "GetCallTreeRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e GetCallTreeRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGetCallTreeRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GetCallTreeRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GetCallTreeRequestValidationError{}
+
+// Validate checks the field values on GetCallTreeResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *GetCallTreeResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on GetCallTreeResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// GetCallTreeResponseMultiError, or nil if none found.
+func (m *GetCallTreeResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *GetCallTreeResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetRoot()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GetCallTreeResponseValidationError{
+					field:  "Root",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GetCallTreeResponseValidationError{
+					field:  "Root",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetRoot()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GetCallTreeResponseValidationError{
+				field:  "Root",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return GetCallTreeResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// GetCallTreeResponseMultiError is an error wrapping multiple validation
+// errors returned by GetCallTreeResponse.ValidateAll() if the designated
+// constraints aren't met.
+type GetCallTreeResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GetCallTreeResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GetCallTreeResponseMultiError) AllErrors() []error { return m }
+
+// GetCallTreeResponseValidationError is the validation error returned by
+// GetCallTreeResponse.Validate if the designated constraints aren't met.
+type GetCallTreeResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GetCallTreeResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GetCallTreeResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GetCallTreeResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GetCallTreeResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GetCallTreeResponseValidationError) ErrorName() string {
+	return "GetCallTreeResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e GetCallTreeResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGetCallTreeResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GetCallTreeResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GetCallTreeResponseValidationError{}
+
+// Validate checks the field values on CallNode with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *CallNode) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on CallNode with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in CallNodeMultiError, or nil
+// if none found.
+func (m *CallNode) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *CallNode) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for RequestId
+
+	// no validation rules for Name
+
+	// no validation rules for Done
+
+	if all {
+		switch v := interface{}(m.GetStatus()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, CallNodeValidationError{
+					field:  "Status",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, CallNodeValidationError{
+					field:  "Status",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetStatus()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return CallNodeValidationError{
+				field:  "Status",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	for idx, item := range m.GetChildren() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, CallNodeValidationError{
+						field:  fmt.Sprintf("Children[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, CallNodeValidationError{
+						field:  fmt.Sprintf("Children[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return CallNodeValidationError{
+					field:  fmt.Sprintf("Children[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	// no validation rules for Detached
+
+	if len(errors) > 0 {
+		return CallNodeMultiError(errors)
+	}
+
+	return nil
+}
+
+// CallNodeMultiError is an error wrapping multiple validation errors returned
+// by CallNode.ValidateAll() if the designated constraints aren't met.
+type CallNodeMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CallNodeMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CallNodeMultiError) AllErrors() []error { return m }
+
+// CallNodeValidationError is the validation error returned by
+// CallNode.Validate if the designated constraints aren't met.
+type CallNodeValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CallNodeValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CallNodeValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CallNodeValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CallNodeValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CallNodeValidationError) ErrorName() string { return "CallNodeValidationError" }
+
+// Error satisfies the builtin error interface
+func (e CallNodeValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCallNode.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CallNodeValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CallNodeValidationError{}
+
+// Validate checks the field values on ListOperationsRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ListOperationsRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ListOperationsRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ListOperationsRequestMultiError, or nil if none found.
+func (m *ListOperationsRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ListOperationsRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Name
+
+	// no validation rules for Status
+
+	// no validation rules for Labels
+
+	if all {
+		switch v := interface{}(m.GetSubmittedAfter()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ListOperationsRequestValidationError{
+					field:  "SubmittedAfter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ListOperationsRequestValidationError{
+					field:  "SubmittedAfter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetSubmittedAfter()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ListOperationsRequestValidationError{
+				field:  "SubmittedAfter",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetSubmittedBefore()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ListOperationsRequestValidationError{
+					field:  "SubmittedBefore",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ListOperationsRequestValidationError{
+					field:  "SubmittedBefore",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetSubmittedBefore()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ListOperationsRequestValidationError{
+				field:  "SubmittedBefore",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for TopLevel
+
+	// no validation rules for PageSize
+
+	// no validation rules for PageToken
+
+	if len(errors) > 0 {
+		return ListOperationsRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// ListOperationsRequestMultiError is an error wrapping multiple validation
+// errors returned by ListOperationsRequest.ValidateAll() if the designated
+// constraints aren't met.
+type ListOperationsRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ListOperationsRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ListOperationsRequestMultiError) AllErrors() []error { return m }
+
+// ListOperationsRequestValidationError is the validation error returned by
+// ListOperationsRequest.Validate if the designated constraints aren't met.
+type ListOperationsRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ListOperationsRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ListOperationsRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ListOperationsRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ListOperationsRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ListOperationsRequestValidationError) ErrorName() string {
+	return "ListOperationsRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ListOperationsRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sListOperationsRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ListOperationsRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ListOperationsRequestValidationError{}
+
+// Validate checks the field values on ListOperationsResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ListOperationsResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ListOperationsResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ListOperationsResponseMultiError, or nil if none found.
+func (m *ListOperationsResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ListOperationsResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	for idx, item := range m.GetOperations() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, ListOperationsResponseValidationError{
+						field:  fmt.Sprintf("Operations[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, ListOperationsResponseValidationError{
+						field:  fmt.Sprintf("Operations[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return ListOperationsResponseValidationError{
+					field:  fmt.Sprintf("Operations[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	// no validation rules for NextPageToken
+
+	if len(errors) > 0 {
+		return ListOperationsResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// ListOperationsResponseMultiError is an error wrapping multiple validation
+// errors returned by ListOperationsResponse.ValidateAll() if the designated
+// constraints aren't met.
+type ListOperationsResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ListOperationsResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ListOperationsResponseMultiError) AllErrors() []error { return m }
+
+// ListOperationsResponseValidationError is the validation error returned by
+// ListOperationsResponse.Validate if the designated constraints aren't met.
+type ListOperationsResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ListOperationsResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ListOperationsResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ListOperationsResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ListOperationsResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ListOperationsResponseValidationError) ErrorName() string {
+	return "ListOperationsResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ListOperationsResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sListOperationsResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ListOperationsResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ListOperationsResponseValidationError{}
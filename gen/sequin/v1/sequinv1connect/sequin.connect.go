@@ -40,14 +40,19 @@ const (
 	SequinServiceGetProcedure = "/arg0net.sequin.v1.SequinService/Get"
 	// SequinServiceExecProcedure is the fully-qualified name of the SequinService's Exec RPC.
 	SequinServiceExecProcedure = "/arg0net.sequin.v1.SequinService/Exec"
-)
-
-// These variables are the protoreflect.Descriptor objects for the RPCs defined in this package.
-var (
-	sequinServiceServiceDescriptor     = v1.File_sequin_v1_sequin_proto.Services().ByName("SequinService")
-	sequinServiceStartMethodDescriptor = sequinServiceServiceDescriptor.Methods().ByName("Start")
-	sequinServiceGetMethodDescriptor   = sequinServiceServiceDescriptor.Methods().ByName("Get")
-	sequinServiceExecMethodDescriptor  = sequinServiceServiceDescriptor.Methods().ByName("Exec")
+	// SequinServiceWatchProcedure is the fully-qualified name of the SequinService's Watch RPC.
+	SequinServiceWatchProcedure = "/arg0net.sequin.v1.SequinService/Watch"
+	// SequinServiceCancelProcedure is the fully-qualified name of the SequinService's Cancel RPC.
+	SequinServiceCancelProcedure = "/arg0net.sequin.v1.SequinService/Cancel"
+	// SequinServiceListEndpointsProcedure is the fully-qualified name of the SequinService's
+	// ListEndpoints RPC.
+	SequinServiceListEndpointsProcedure = "/arg0net.sequin.v1.SequinService/ListEndpoints"
+	// SequinServiceGetCallTreeProcedure is the fully-qualified name of the SequinService's GetCallTree
+	// RPC.
+	SequinServiceGetCallTreeProcedure = "/arg0net.sequin.v1.SequinService/GetCallTree"
+	// SequinServiceListOperationsProcedure is the fully-qualified name of the SequinService's
+	// ListOperations RPC.
+	SequinServiceListOperationsProcedure = "/arg0net.sequin.v1.SequinService/ListOperations"
 )
 
 // SequinServiceClient is a client for the arg0net.sequin.v1.SequinService service.
@@ -59,6 +64,19 @@ type SequinServiceClient interface {
 	// Exec starts an operation and always streams back operation updates.
 	// This is preferred over Start/Get for long-running operations.
 	Exec(context.Context, *connect.Request[v1.ExecRequest]) (*connect.ServerStreamForClient[longrunningpb.Operation], error)
+	// Watch streams back operation updates but will not start an operation.
+	Watch(context.Context, *connect.Request[v1.WatchRequest]) (*connect.ServerStreamForClient[longrunningpb.Operation], error)
+	// Cancel cancels an ongoing operation.
+	// Note that this may not be possible for all operations.
+	Cancel(context.Context, *connect.Request[v1.CancelRequest]) (*connect.Response[v1.CancelResponse], error)
+	// ListEndpoints describes the endpoints which the server can execute.
+	ListEndpoints(context.Context, *connect.Request[v1.ListEndpointsRequest]) (*connect.Response[v1.ListEndpointsResponse], error)
+	// GetCallTree returns the state of an operation along with the calls it
+	// made, recursively.
+	GetCallTree(context.Context, *connect.Request[v1.GetCallTreeRequest]) (*connect.Response[v1.GetCallTreeResponse], error)
+	// ListOperations returns the operations matching the filters, most
+	// recently submitted first.
+	ListOperations(context.Context, *connect.Request[v1.ListOperationsRequest]) (*connect.Response[v1.ListOperationsResponse], error)
 }
 
 // NewSequinServiceClient constructs a client for the arg0net.sequin.v1.SequinService service. By
@@ -70,23 +88,54 @@ type SequinServiceClient interface {
 // http://api.acme.com or https://acme.com/grpc).
 func NewSequinServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) SequinServiceClient {
 	baseURL = strings.TrimRight(baseURL, "/")
+	sequinServiceMethods := v1.File_sequin_v1_sequin_proto.Services().ByName("SequinService").Methods()
 	return &sequinServiceClient{
 		start: connect.NewClient[v1.StartRequest, v1.StartResponse](
 			httpClient,
 			baseURL+SequinServiceStartProcedure,
-			connect.WithSchema(sequinServiceStartMethodDescriptor),
+			connect.WithSchema(sequinServiceMethods.ByName("Start")),
 			connect.WithClientOptions(opts...),
 		),
 		get: connect.NewClient[v1.GetRequest, v1.GetResponse](
 			httpClient,
 			baseURL+SequinServiceGetProcedure,
-			connect.WithSchema(sequinServiceGetMethodDescriptor),
+			connect.WithSchema(sequinServiceMethods.ByName("Get")),
 			connect.WithClientOptions(opts...),
 		),
 		exec: connect.NewClient[v1.ExecRequest, longrunningpb.Operation](
 			httpClient,
 			baseURL+SequinServiceExecProcedure,
-			connect.WithSchema(sequinServiceExecMethodDescriptor),
+			connect.WithSchema(sequinServiceMethods.ByName("Exec")),
+			connect.WithClientOptions(opts...),
+		),
+		watch: connect.NewClient[v1.WatchRequest, longrunningpb.Operation](
+			httpClient,
+			baseURL+SequinServiceWatchProcedure,
+			connect.WithSchema(sequinServiceMethods.ByName("Watch")),
+			connect.WithClientOptions(opts...),
+		),
+		cancel: connect.NewClient[v1.CancelRequest, v1.CancelResponse](
+			httpClient,
+			baseURL+SequinServiceCancelProcedure,
+			connect.WithSchema(sequinServiceMethods.ByName("Cancel")),
+			connect.WithClientOptions(opts...),
+		),
+		listEndpoints: connect.NewClient[v1.ListEndpointsRequest, v1.ListEndpointsResponse](
+			httpClient,
+			baseURL+SequinServiceListEndpointsProcedure,
+			connect.WithSchema(sequinServiceMethods.ByName("ListEndpoints")),
+			connect.WithClientOptions(opts...),
+		),
+		getCallTree: connect.NewClient[v1.GetCallTreeRequest, v1.GetCallTreeResponse](
+			httpClient,
+			baseURL+SequinServiceGetCallTreeProcedure,
+			connect.WithSchema(sequinServiceMethods.ByName("GetCallTree")),
+			connect.WithClientOptions(opts...),
+		),
+		listOperations: connect.NewClient[v1.ListOperationsRequest, v1.ListOperationsResponse](
+			httpClient,
+			baseURL+SequinServiceListOperationsProcedure,
+			connect.WithSchema(sequinServiceMethods.ByName("ListOperations")),
 			connect.WithClientOptions(opts...),
 		),
 	}
@@ -94,9 +143,14 @@ func NewSequinServiceClient(httpClient connect.HTTPClient, baseURL string, opts
 
 // sequinServiceClient implements SequinServiceClient.
 type sequinServiceClient struct {
-	start *connect.Client[v1.StartRequest, v1.StartResponse]
-	get   *connect.Client[v1.GetRequest, v1.GetResponse]
-	exec  *connect.Client[v1.ExecRequest, longrunningpb.Operation]
+	start          *connect.Client[v1.StartRequest, v1.StartResponse]
+	get            *connect.Client[v1.GetRequest, v1.GetResponse]
+	exec           *connect.Client[v1.ExecRequest, longrunningpb.Operation]
+	watch          *connect.Client[v1.WatchRequest, longrunningpb.Operation]
+	cancel         *connect.Client[v1.CancelRequest, v1.CancelResponse]
+	listEndpoints  *connect.Client[v1.ListEndpointsRequest, v1.ListEndpointsResponse]
+	getCallTree    *connect.Client[v1.GetCallTreeRequest, v1.GetCallTreeResponse]
+	listOperations *connect.Client[v1.ListOperationsRequest, v1.ListOperationsResponse]
 }
 
 // Start calls arg0net.sequin.v1.SequinService.Start.
@@ -114,6 +168,31 @@ func (c *sequinServiceClient) Exec(ctx context.Context, req *connect.Request[v1.
 	return c.exec.CallServerStream(ctx, req)
 }
 
+// Watch calls arg0net.sequin.v1.SequinService.Watch.
+func (c *sequinServiceClient) Watch(ctx context.Context, req *connect.Request[v1.WatchRequest]) (*connect.ServerStreamForClient[longrunningpb.Operation], error) {
+	return c.watch.CallServerStream(ctx, req)
+}
+
+// Cancel calls arg0net.sequin.v1.SequinService.Cancel.
+func (c *sequinServiceClient) Cancel(ctx context.Context, req *connect.Request[v1.CancelRequest]) (*connect.Response[v1.CancelResponse], error) {
+	return c.cancel.CallUnary(ctx, req)
+}
+
+// ListEndpoints calls arg0net.sequin.v1.SequinService.ListEndpoints.
+func (c *sequinServiceClient) ListEndpoints(ctx context.Context, req *connect.Request[v1.ListEndpointsRequest]) (*connect.Response[v1.ListEndpointsResponse], error) {
+	return c.listEndpoints.CallUnary(ctx, req)
+}
+
+// GetCallTree calls arg0net.sequin.v1.SequinService.GetCallTree.
+func (c *sequinServiceClient) GetCallTree(ctx context.Context, req *connect.Request[v1.GetCallTreeRequest]) (*connect.Response[v1.GetCallTreeResponse], error) {
+	return c.getCallTree.CallUnary(ctx, req)
+}
+
+// ListOperations calls arg0net.sequin.v1.SequinService.ListOperations.
+func (c *sequinServiceClient) ListOperations(ctx context.Context, req *connect.Request[v1.ListOperationsRequest]) (*connect.Response[v1.ListOperationsResponse], error) {
+	return c.listOperations.CallUnary(ctx, req)
+}
+
 // SequinServiceHandler is an implementation of the arg0net.sequin.v1.SequinService service.
 type SequinServiceHandler interface {
 	// Start begins a new operation.
@@ -123,6 +202,19 @@ type SequinServiceHandler interface {
 	// Exec starts an operation and always streams back operation updates.
 	// This is preferred over Start/Get for long-running operations.
 	Exec(context.Context, *connect.Request[v1.ExecRequest], *connect.ServerStream[longrunningpb.Operation]) error
+	// Watch streams back operation updates but will not start an operation.
+	Watch(context.Context, *connect.Request[v1.WatchRequest], *connect.ServerStream[longrunningpb.Operation]) error
+	// Cancel cancels an ongoing operation.
+	// Note that this may not be possible for all operations.
+	Cancel(context.Context, *connect.Request[v1.CancelRequest]) (*connect.Response[v1.CancelResponse], error)
+	// ListEndpoints describes the endpoints which the server can execute.
+	ListEndpoints(context.Context, *connect.Request[v1.ListEndpointsRequest]) (*connect.Response[v1.ListEndpointsResponse], error)
+	// GetCallTree returns the state of an operation along with the calls it
+	// made, recursively.
+	GetCallTree(context.Context, *connect.Request[v1.GetCallTreeRequest]) (*connect.Response[v1.GetCallTreeResponse], error)
+	// ListOperations returns the operations matching the filters, most
+	// recently submitted first.
+	ListOperations(context.Context, *connect.Request[v1.ListOperationsRequest]) (*connect.Response[v1.ListOperationsResponse], error)
 }
 
 // NewSequinServiceHandler builds an HTTP handler from the service implementation. It returns the
@@ -131,22 +223,53 @@ type SequinServiceHandler interface {
 // By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
 // and JSON codecs. They also support gzip compression.
 func NewSequinServiceHandler(svc SequinServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	sequinServiceMethods := v1.File_sequin_v1_sequin_proto.Services().ByName("SequinService").Methods()
 	sequinServiceStartHandler := connect.NewUnaryHandler(
 		SequinServiceStartProcedure,
 		svc.Start,
-		connect.WithSchema(sequinServiceStartMethodDescriptor),
+		connect.WithSchema(sequinServiceMethods.ByName("Start")),
 		connect.WithHandlerOptions(opts...),
 	)
 	sequinServiceGetHandler := connect.NewUnaryHandler(
 		SequinServiceGetProcedure,
 		svc.Get,
-		connect.WithSchema(sequinServiceGetMethodDescriptor),
+		connect.WithSchema(sequinServiceMethods.ByName("Get")),
 		connect.WithHandlerOptions(opts...),
 	)
 	sequinServiceExecHandler := connect.NewServerStreamHandler(
 		SequinServiceExecProcedure,
 		svc.Exec,
-		connect.WithSchema(sequinServiceExecMethodDescriptor),
+		connect.WithSchema(sequinServiceMethods.ByName("Exec")),
+		connect.WithHandlerOptions(opts...),
+	)
+	sequinServiceWatchHandler := connect.NewServerStreamHandler(
+		SequinServiceWatchProcedure,
+		svc.Watch,
+		connect.WithSchema(sequinServiceMethods.ByName("Watch")),
+		connect.WithHandlerOptions(opts...),
+	)
+	sequinServiceCancelHandler := connect.NewUnaryHandler(
+		SequinServiceCancelProcedure,
+		svc.Cancel,
+		connect.WithSchema(sequinServiceMethods.ByName("Cancel")),
+		connect.WithHandlerOptions(opts...),
+	)
+	sequinServiceListEndpointsHandler := connect.NewUnaryHandler(
+		SequinServiceListEndpointsProcedure,
+		svc.ListEndpoints,
+		connect.WithSchema(sequinServiceMethods.ByName("ListEndpoints")),
+		connect.WithHandlerOptions(opts...),
+	)
+	sequinServiceGetCallTreeHandler := connect.NewUnaryHandler(
+		SequinServiceGetCallTreeProcedure,
+		svc.GetCallTree,
+		connect.WithSchema(sequinServiceMethods.ByName("GetCallTree")),
+		connect.WithHandlerOptions(opts...),
+	)
+	sequinServiceListOperationsHandler := connect.NewUnaryHandler(
+		SequinServiceListOperationsProcedure,
+		svc.ListOperations,
+		connect.WithSchema(sequinServiceMethods.ByName("ListOperations")),
 		connect.WithHandlerOptions(opts...),
 	)
 	return "/arg0net.sequin.v1.SequinService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -157,6 +280,16 @@ func NewSequinServiceHandler(svc SequinServiceHandler, opts ...connect.HandlerOp
 			sequinServiceGetHandler.ServeHTTP(w, r)
 		case SequinServiceExecProcedure:
 			sequinServiceExecHandler.ServeHTTP(w, r)
+		case SequinServiceWatchProcedure:
+			sequinServiceWatchHandler.ServeHTTP(w, r)
+		case SequinServiceCancelProcedure:
+			sequinServiceCancelHandler.ServeHTTP(w, r)
+		case SequinServiceListEndpointsProcedure:
+			sequinServiceListEndpointsHandler.ServeHTTP(w, r)
+		case SequinServiceGetCallTreeProcedure:
+			sequinServiceGetCallTreeHandler.ServeHTTP(w, r)
+		case SequinServiceListOperationsProcedure:
+			sequinServiceListOperationsHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -177,3 +310,23 @@ func (UnimplementedSequinServiceHandler) Get(context.Context, *connect.Request[v
 func (UnimplementedSequinServiceHandler) Exec(context.Context, *connect.Request[v1.ExecRequest], *connect.ServerStream[longrunningpb.Operation]) error {
 	return connect.NewError(connect.CodeUnimplemented, errors.New("arg0net.sequin.v1.SequinService.Exec is not implemented"))
 }
+
+func (UnimplementedSequinServiceHandler) Watch(context.Context, *connect.Request[v1.WatchRequest], *connect.ServerStream[longrunningpb.Operation]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("arg0net.sequin.v1.SequinService.Watch is not implemented"))
+}
+
+func (UnimplementedSequinServiceHandler) Cancel(context.Context, *connect.Request[v1.CancelRequest]) (*connect.Response[v1.CancelResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("arg0net.sequin.v1.SequinService.Cancel is not implemented"))
+}
+
+func (UnimplementedSequinServiceHandler) ListEndpoints(context.Context, *connect.Request[v1.ListEndpointsRequest]) (*connect.Response[v1.ListEndpointsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("arg0net.sequin.v1.SequinService.ListEndpoints is not implemented"))
+}
+
+func (UnimplementedSequinServiceHandler) GetCallTree(context.Context, *connect.Request[v1.GetCallTreeRequest]) (*connect.Response[v1.GetCallTreeResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("arg0net.sequin.v1.SequinService.GetCallTree is not implemented"))
+}
+
+func (UnimplementedSequinServiceHandler) ListOperations(context.Context, *connect.Request[v1.ListOperationsRequest]) (*connect.Response[v1.ListOperationsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("arg0net.sequin.v1.SequinService.ListOperations is not implemented"))
+}
@@ -2,11 +2,25 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"reflect"
+
+	"google.golang.org/protobuf/proto"
 )
 
+var contextType = reflect.TypeFor[context.Context]()
+var errorType = reflect.TypeFor[error]()
+var protoMessageType = reflect.TypeFor[proto.Message]()
+
+// IsProtoMessage returns true if values of the type are protobuf messages,
+// which are encoded using the protobuf wire format rather than gob.
+func IsProtoMessage(t reflect.Type) bool {
+	return t.Kind() == reflect.Pointer && t.Implements(protoMessageType)
+}
+
 func EncodeVarint(value int, buf [10]byte) []byte {
 	n := 0
 	for value >= 0x80 {
@@ -23,6 +37,13 @@ func Encode(v reflect.Value) ([]byte, error) {
 	switch {
 	case v.IsZero():
 		return []byte{}, nil
+	case IsProtoMessage(at):
+		// Deterministic output is required for stable request IDs.
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(v.Interface().(proto.Message))
+		if err != nil {
+			return nil, fmt.Errorf("proto encode failed on type %q: %w", at.String(), err)
+		}
+		return data, nil
 	default:
 		var buf bytes.Buffer
 		enc := gob.NewEncoder(&buf)
@@ -42,6 +63,10 @@ func Decode(data []byte, vt reflect.Type) (reflect.Value, error) {
 
 	switch {
 	case len(data) == 0:
+	case IsProtoMessage(vt):
+		if err := proto.Unmarshal(data, val.Interface().(proto.Message)); err != nil {
+			return val, fmt.Errorf("proto decode failed on type %q: %w", vt.String(), err)
+		}
 	default:
 		dec := gob.NewDecoder(bytes.NewReader(data))
 		if err := dec.DecodeValue(val); err != nil {
@@ -54,3 +79,109 @@ func Decode(data []byte, vt reflect.Type) (reflect.Value, error) {
 	}
 	return val.Elem(), nil
 }
+
+// EncodeValues encodes a list of argument or result values.
+// See EncodeValue for details.
+func EncodeValues(values []reflect.Value) ([][]byte, error) {
+	data := make([][]byte, len(values))
+	for i, v := range values {
+		d, err := EncodeValue(v)
+		if err != nil {
+			return nil, err
+		}
+		data[i] = d
+	}
+	return data, nil
+}
+
+// Tags which start an encoded error, recording the context error it wraps,
+// if any. A nil error is encoded as no data, so a stored failure never
+// decodes as success, even if its message is empty.
+const (
+	errorTag byte = iota + 1
+	canceledTag
+	deadlineTag
+)
+
+// EncodeValue encodes an argument or result value.
+//
+// Context values are skipped and left empty. Error values are stored as their
+// message, since concrete error types are rarely encodable, along with whether
+// they wrap context.Canceled or context.DeadlineExceeded.
+func EncodeValue(v reflect.Value) ([]byte, error) {
+	switch v.Type() {
+	case contextType:
+		return nil, nil
+	case errorType:
+		if v.IsNil() {
+			return nil, nil
+		}
+		err := v.Interface().(error)
+		tag := errorTag
+		switch {
+		case errors.Is(err, context.Canceled):
+			tag = canceledTag
+		case errors.Is(err, context.DeadlineExceeded):
+			tag = deadlineTag
+		}
+		return append([]byte{tag}, err.Error()...), nil
+	}
+	return Encode(v)
+}
+
+// DecodeValues decodes values encoded by EncodeValues into the given types.
+// See DecodeValue for details.
+func DecodeValues(data [][]byte, types []reflect.Type) ([]reflect.Value, error) {
+	if len(data) != len(types) {
+		return nil, fmt.Errorf("expected %d values, got %d", len(types), len(data))
+	}
+	values := make([]reflect.Value, len(data))
+	for i, d := range data {
+		val, err := DecodeValue(d, types[i])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = val
+	}
+	return values, nil
+}
+
+// DecodeValue decodes a value encoded by EncodeValue into the given type.
+//
+// Context values are left unset, and must be filled in by the caller. Errors
+// which wrapped a context error wrap the same error when decoded.
+func DecodeValue(data []byte, vt reflect.Type) (reflect.Value, error) {
+	switch vt {
+	case contextType:
+		return reflect.Value{}, nil
+	case errorType:
+		var err error
+		if len(data) > 0 {
+			err = decodeError(data)
+		}
+		return reflect.ValueOf(&err).Elem(), nil
+	}
+	return Decode(data, vt)
+}
+
+// storedError is an error decoded from its stored message.
+type storedError struct {
+	msg    string
+	target error // Context error wrapped by the original error, if any.
+}
+
+func (e *storedError) Error() string { return e.msg }
+func (e *storedError) Unwrap() error { return e.target }
+
+func decodeError(data []byte) error {
+	switch data[0] {
+	case errorTag:
+		return &storedError{msg: string(data[1:])}
+	case canceledTag:
+		return &storedError{msg: string(data[1:]), target: context.Canceled}
+	case deadlineTag:
+		return &storedError{msg: string(data[1:]), target: context.DeadlineExceeded}
+	}
+	// Errors stored by earlier versions have no tag.
+	return &storedError{msg: string(data)}
+}
@@ -1,10 +1,15 @@
 package internal
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func TestCodec(t *testing.T) {
@@ -27,6 +32,61 @@ func TestCodec_Proto(t *testing.T) {
 	require.EqualValues(t, arg1, res.Interface())
 }
 
+func TestCodec_ProtoMessage(t *testing.T) {
+	arg1 := wrapperspb.String("hello")
+	data, err := Encode(reflect.ValueOf(arg1))
+	require.NoError(t, err)
+
+	res, err := Decode(data, reflect.TypeOf(arg1))
+	require.NoError(t, err)
+	require.True(t, proto.Equal(arg1, res.Interface().(proto.Message)))
+}
+
 type fakeProto struct {
 	Data string
 }
+
+func TestCodec_Values(t *testing.T) {
+	types := []reflect.Type{reflect.TypeFor[string](), reflect.TypeFor[error]()}
+	failure := errors.New("failed")
+	in := []reflect.Value{
+		reflect.ValueOf("result"),
+		reflect.ValueOf(&failure).Elem(),
+	}
+	data, err := EncodeValues(in)
+	require.NoError(t, err)
+
+	out, err := DecodeValues(data, types)
+	require.NoError(t, err)
+	require.Equal(t, "result", out[0].String())
+	require.EqualError(t, out[1].Interface().(error), "failed")
+
+	_, err = DecodeValues(data[:1], types)
+	require.Error(t, err)
+}
+
+func TestCodec_Errors(t *testing.T) {
+	for _, in := range []error{
+		errors.New(""),
+		errors.New("failed"),
+		fmt.Errorf("step: %w", context.Canceled),
+		context.DeadlineExceeded,
+	} {
+		data, err := EncodeValue(reflect.ValueOf(&in).Elem())
+		require.NoError(t, err)
+		val, err := DecodeValue(data, reflect.TypeFor[error]())
+		require.NoError(t, err)
+		out, _ := val.Interface().(error)
+		require.Error(t, out)
+		require.Equal(t, in.Error(), out.Error())
+		require.Equal(t, errors.Is(in, context.Canceled), errors.Is(out, context.Canceled))
+		require.Equal(t, errors.Is(in, context.DeadlineExceeded), errors.Is(out, context.DeadlineExceeded))
+	}
+
+	var none error
+	data, err := EncodeValue(reflect.ValueOf(&none).Elem())
+	require.NoError(t, err)
+	val, err := DecodeValue(data, reflect.TypeFor[error]())
+	require.NoError(t, err)
+	require.Nil(t, val.Interface())
+}
@@ -1,4 +1,13 @@
 package internal
 
+// KeyPrefix is the prefix of metadata keys reserved for sequin options.
+const KeyPrefix = "sequin."
+
 // GlobalIDGen is the key for the global ID generation option.
-const GlobalIDGen = "sequin.globalID"
+const GlobalIDGen = KeyPrefix + "globalID"
+
+// ArgNames is the key for the argument names option.
+const ArgNames = KeyPrefix + "argNames"
+
+// Compensation is the key for the compensating endpoint option.
+const Compensation = KeyPrefix + "compensation"
@@ -15,6 +15,13 @@ func (md MDKey[T]) Get(ctx context.Context) T {
 	return v.(T)
 }
 
+// Lookup returns the metadata value stored in the context, and whether it
+// was found.
+func (md MDKey[T]) Lookup(ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(md).(T)
+	return v, ok
+}
+
 // Set stores the metadata value in the context.
 func (md MDKey[T]) Set(ctx context.Context, v T) context.Context {
 	return context.WithValue(ctx, md, v)
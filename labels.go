@@ -0,0 +1,37 @@
+package sequin
+
+import (
+	"context"
+	"maps"
+
+	"github.com/vgough/sequin/internal"
+)
+
+// labelSet is the type of labels stored in a context.
+type labelSet map[string]string
+
+var labelsMD internal.MDKey[labelSet]
+
+// WithLabels returns a context holding the labels, along with those already
+// in the context. Labels with the same key replace existing ones.
+//
+// Labels annotate the calls made with the context. The runtime stores them
+// with each request, and makes them available to its execution, so calls
+// made by a step inherit the labels of the step.
+func WithLabels(ctx context.Context, labels map[string]string) context.Context {
+	if len(labels) == 0 {
+		return ctx
+	}
+	merged := maps.Clone(labelsMD.Get(ctx))
+	if merged == nil {
+		merged = make(labelSet, len(labels))
+	}
+	maps.Copy(merged, labels)
+	return labelsMD.Set(ctx, merged)
+}
+
+// Labels returns a copy of the labels stored in the context, or nil if there
+// are none.
+func Labels(ctx context.Context) map[string]string {
+	return maps.Clone(labelsMD.Get(ctx))
+}
@@ -0,0 +1,133 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/vgough/sequin"
+	"github.com/vgough/sequin/internal"
+	"github.com/vgough/sequin/registry"
+)
+
+// ErrLateCompensation is returned by calls with a compensation which complete
+// after the execution which made them has completed, as the compensation can
+// no longer be recorded.
+var ErrLateCompensation = errors.New("local: execution completed before compensation was recorded")
+
+// Compensation is a compensating call recorded when a child request
+// completes, to undo its effects if the execution which issued it fails.
+type Compensation struct {
+	StepID string   // Child request which the compensation undoes.
+	Name   string   // Compensating endpoint name.
+	Args   [][]byte // Encoded arguments of the child request.
+
+	RequestID string // Compensating request, set once the call is made.
+	Done      bool   // Set once the compensating call has completed.
+	Error     string // Error of the compensating call, if it failed.
+}
+
+// compensating is set in the context of compensating calls.
+type compensating bool
+
+var compensatingMD internal.MDKey[compensating]
+
+// recordCompensation records the compensation of a child request which
+// completed, if the call has one.
+func (e *execution) recordCompensation(ctx context.Context, ep *registry.Endpoint, stepID string,
+	data [][]byte) error {
+	name := sequin.Compensation(ctx, ep)
+	if name == "" || bool(compensatingMD.Get(ctx)) {
+		return nil
+	}
+	comp := e.s.registry.GetEndpoint(name)
+	switch {
+	case comp == nil:
+		return fmt.Errorf("unknown compensating endpoint %s for %s", name, ep.Name)
+	case !sameArgs(ep, comp):
+		return fmt.Errorf("compensating endpoint %s does not take the arguments of %s", name, ep.Name)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		// The request has been, or is being, stored as completed.
+		return fmt.Errorf("%w: %s", ErrLateCompensation, e.req.ID)
+	}
+	// Completed requests are answered again when the execution is resumed.
+	if slices.ContainsFunc(e.req.Compensations, func(c Compensation) bool { return c.StepID == stepID }) {
+		return nil
+	}
+	e.req.Compensations = append(e.req.Compensations, Compensation{StepID: stepID, Name: name, Args: data})
+	return e.s.save(ctx, e.req)
+}
+
+// compensate makes the compensating calls recorded by a failed execution, in
+// the reverse order that their steps completed. Failed compensations are
+// recorded, and don't stop the remaining compensations from being made.
+//
+// Returns an error if interrupted by shutdown or a crash, leaving the
+// remaining compensations to be made when the request is resumed.
+func (s *Server) compensate(ctx context.Context, e *execution) error {
+	// Compensations are made even if the execution was cancelled.
+	ctx = compensatingMD.Set(context.WithoutCancel(ctx), true)
+	log := sequin.Logger(ctx)
+	for i := len(e.req.Compensations) - 1; i >= 0; i-- {
+		e.mu.Lock()
+		c := e.req.Compensations[i]
+		e.mu.Unlock()
+		if c.Done {
+			continue
+		}
+
+		err := s.callCompensation(ctx, &c)
+		if errors.Is(err, ErrShutdown) || errors.Is(err, ErrCrashed) {
+			return err
+		}
+		c.Done = true
+		if err != nil {
+			c.Error = err.Error()
+			log.Log(ctx, s.logLevels.Error, "sequin: compensation failed",
+				"compensation", c.Name, "step", c.StepID, "error", err)
+		}
+
+		e.mu.Lock()
+		e.req.Compensations[i] = c
+		err = s.save(ctx, e.req)
+		e.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callCompensation makes a compensating call, setting its request ID.
+func (s *Server) callCompensation(ctx context.Context, c *Compensation) error {
+	ep := s.registry.GetEndpoint(c.Name)
+	if ep == nil {
+		return errors.New("unknown function: " + c.Name)
+	}
+	args, err := ep.DecodeArgs(c.Args)
+	if err != nil {
+		return err
+	}
+	ep.SetContext(s.OnIssued(ctx, func(id string) { c.RequestID = id }), args)
+	return ep.GetError(s.Exec(ep, args))
+}
+
+// sameArgs reports whether a compensating endpoint takes the encoded
+// arguments of a step.
+func sameArgs(step, comp *registry.Endpoint) bool {
+	if len(step.InputTypes) != len(comp.InputTypes) || step.ContextIndex != comp.ContextIndex {
+		return false
+	}
+	for i, t := range step.InputTypes {
+		if step.IsInjected(i) != comp.IsInjected(i) ||
+			!step.IsInjected(i) && t != comp.InputTypes[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/vgough/sequin"
+)
+
+// ErrCrashed is returned by a Server once it has simulated a crash.
+var ErrCrashed = errors.New("local: simulated crash")
+
+// CrashTest verifies that a workflow interrupted at any step resumes
+// correctly.
+//
+// The call is first run to completion on a fresh server, to determine the
+// expected result and the requests it completes. Then, for each of those
+// requests, the call is run on a server which crashes as soon as that request
+// completes, and is run again on a new server over the same store. Across the
+// two runs, every request must complete exactly once, and the final result must
+// match the uninterrupted run.
+//
+// The call is passed a context holding the server's runtime, and should invoke
+// the registered top-level function of the workflow.
+func CrashTest[T any](t testing.TB, call func(ctx context.Context) (T, error)) {
+	t.Helper()
+
+	// Uninterrupted run.
+	var expected []string
+	s := NewServer()
+	s.onComplete = func(req *Request) {
+		expected = append(expected, req.ID)
+	}
+	want, wantErr := call(sequin.WithRuntime(context.Background(), s))
+	if len(expected) == 0 {
+		t.Fatalf("crash test: workflow did not complete any requests")
+	}
+	slices.Sort(expected)
+
+	for step := 1; step <= len(expected); step++ {
+		store := NewMemoryStore()
+		var completed []string
+		onComplete := func(req *Request) {
+			completed = append(completed, req.ID)
+		}
+
+		s := NewServer(WithStore(store))
+		s.crashAfter = step
+		s.onComplete = onComplete
+		_, err := call(sequin.WithRuntime(context.Background(), s))
+		if !errors.Is(err, ErrCrashed) && !s.isCrashed() {
+			t.Errorf("crash after step %d: server did not crash, got error %v", step, err)
+			continue
+		}
+
+		s = NewServer(WithStore(store))
+		s.onComplete = onComplete
+		got, gotErr := call(sequin.WithRuntime(context.Background(), s))
+
+		if msg := compareResults(want, wantErr, got, gotErr); msg != "" {
+			t.Errorf("crash after step %d: %s", step, msg)
+		}
+		slices.Sort(completed)
+		if !slices.Equal(expected, completed) {
+			t.Errorf("crash after step %d: %s", step, compareCompleted(expected, completed))
+		}
+	}
+}
+
+func compareResults(want any, wantErr error, got any, gotErr error) string {
+	switch {
+	case (wantErr == nil) != (gotErr == nil):
+		return fmt.Sprintf("got error %v, expected %v", gotErr, wantErr)
+	case wantErr != nil && wantErr.Error() != gotErr.Error():
+		return fmt.Sprintf("got error %q, expected %q", gotErr, wantErr)
+	case !reflect.DeepEqual(want, got):
+		return fmt.Sprintf("got result %v, expected %v", got, want)
+	}
+	return ""
+}
+
+// compareCompleted describes the difference between two sorted lists of
+// completed request IDs.
+func compareCompleted(expected, completed []string) string {
+	counts := make(map[string]int)
+	for _, id := range completed {
+		counts[id]++
+	}
+	var repeated, missing, unexpected int
+	for _, id := range expected {
+		switch n := counts[id]; {
+		case n == 0:
+			missing++
+		case n > 1:
+			repeated++
+		}
+		delete(counts, id)
+	}
+	unexpected = len(counts)
+	return fmt.Sprintf("%d requests completed more than once, %d never completed, %d were unexpected",
+		repeated, missing, unexpected)
+}
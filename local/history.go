@@ -0,0 +1,196 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/vgough/sequin"
+	"github.com/vgough/sequin/internal"
+)
+
+// ErrNonDeterministic is returned when a workflow issues child calls which
+// differ from the history recorded by a previous execution.
+var ErrNonDeterministic = errors.New("local: non-deterministic workflow")
+
+// NonDeterminismPolicy determines how a Server reports workflows which diverge
+// from their recorded history.
+//
+// Child calls are compared in the order they are issued, so workflows which
+// issue calls concurrently may be reported even when the set of calls matches.
+type NonDeterminismPolicy int
+
+const (
+	// WarnNonDeterminism logs a warning and continues execution.
+	WarnNonDeterminism NonDeterminismPolicy = iota
+	// FailNonDeterminism fails the call which diverged from history. If
+	// recorded calls were skipped, the workflow itself fails.
+	FailNonDeterminism
+)
+
+// WithNonDeterminism sets how divergence from recorded history is reported.
+// The default is WarnNonDeterminism.
+func WithNonDeterminism(p NonDeterminismPolicy) Option {
+	return func(s *Server) {
+		s.nonDeterminism = p
+	}
+}
+
+var executionMD = internal.MDKey[*execution]{}
+
+// execution tracks the child calls issued while executing a request, and
+// compares them against the history recorded by previous executions.
+type execution struct {
+	s   *Server
+	req *Request
+	ctx context.Context // cancelled when the execution returns.
+
+	mu       sync.Mutex
+	issued   int  // number of children issued by this execution.
+	diverged bool // set once the execution diverged from history.
+	done     bool // set once the execution has returned.
+	closed   bool // set once the calls awaited by the execution have returned.
+
+	pending sync.WaitGroup // calls made by the execution which have not returned.
+}
+
+// begin tracks a call made by the execution, returning false if the execution
+// has returned. The call must be released with pending.Done.
+func (e *execution) begin() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.done {
+		return false
+	}
+	e.pending.Add(1)
+	return true
+}
+
+// issue records a child call, checking it against the recorded history.
+// Recorded calls made under a previous name of the endpoint match any of
+// the aliasIDs.
+func (e *execution) issue(ctx context.Context, c Child, aliasIDs []string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.done {
+		return nil
+	}
+
+	n := e.issued
+	e.issued++
+	history := e.req.Children
+	var err error
+	if n < len(history) {
+		if history[n].ID == c.ID || slices.Contains(aliasIDs, history[n].ID) {
+			return nil
+		}
+		e.diverged = true
+		err = e.reportDivergence(
+			fmt.Sprintf("call %d to %s is not in history", n+1, c.Name),
+			formatHistory(history, n, &c))
+
+		// Replace the remaining history with the calls now being made.
+		e.req.Children = history[:n]
+	}
+
+	e.req.Children = append(e.req.Children, c)
+	if saveErr := e.s.save(ctx, e.req); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// version returns the version recorded for a branch point, recording it if
+// this is the first time the execution reached the branch point.
+func (e *execution) version(ctx context.Context, changeID string, min, max int) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	v, ok := e.req.Versions[changeID]
+	if !ok {
+		v = max
+		if e.issued < len(e.req.Children) {
+			// A previous execution issued calls beyond this point without
+			// reaching the branch point, so predates it.
+			v = sequin.DefaultVersion
+		}
+		if e.req.Versions == nil {
+			e.req.Versions = make(map[string]int)
+		}
+		e.req.Versions[changeID] = v
+		if err := e.s.save(ctx, e.req); err != nil {
+			return 0, err
+		}
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("%w: %s recorded version %d of change %q, supported range is [%d, %d]",
+			sequin.ErrIncompatibleVersion, e.req.Name, v, changeID, min, max)
+	}
+	return v, nil
+}
+
+// finish marks the execution as complete, returning an error if any calls
+// in history were skipped. Calls which are still outstanding, such as those
+// of futures which were never waited on, are waited for, so that their
+// outcomes are stored before the execution completes.
+func (e *execution) finish() error {
+	err := e.checkSkipped()
+	e.pending.Wait()
+	e.mu.Lock()
+	e.closed = true
+	e.mu.Unlock()
+	return err
+}
+
+// checkSkipped marks the execution as done, and reports calls in history
+// which were not issued.
+func (e *execution) checkSkipped() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.done = true
+
+	history := e.req.Children
+	if e.diverged || e.issued >= len(history) {
+		return nil
+	}
+	return e.reportDivergence(
+		fmt.Sprintf("%d calls in history were skipped", len(history)-e.issued),
+		formatHistory(history, e.issued, nil))
+}
+
+// reportDivergence reports non-deterministic execution according to the
+// server's policy. Returns an error if the policy is to fail. Warnings are
+// logged with the execution's logger, which identifies the request.
+func (e *execution) reportDivergence(msg, diff string) error {
+	if e.s.nonDeterminism == FailNonDeterminism {
+		return fmt.Errorf("%w: %s: %s\n%s", ErrNonDeterministic, e.req.Name, msg, diff)
+	}
+	sequin.Logger(e.ctx).Warn("non-deterministic workflow", "reason", msg, "diff", diff)
+	return nil
+}
+
+// formatHistory returns a diff between the recorded history and the call issued
+// at position n. If got is nil, the history from position n was skipped.
+func formatHistory(history []Child, n int, got *Child) string {
+	const contextLines = 3
+
+	var b strings.Builder
+	end := min(len(history), n+contextLines+1)
+	for i := max(0, n-contextLines); i < end; i++ {
+		prefix := " "
+		if i >= n {
+			prefix = "-"
+		}
+		fmt.Fprintf(&b, "%s %3d %s %s\n", prefix, i+1, history[i].Name, history[i].ID)
+		if i == n && got != nil {
+			fmt.Fprintf(&b, "+ %3d %s %s\n", i+1, got.Name, got.ID)
+		}
+	}
+	if end < len(history) {
+		fmt.Fprintf(&b, "- ... %d more\n", len(history)-end)
+	}
+	return b.String()
+}
@@ -0,0 +1,131 @@
+package local
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrShutdown is returned for top-level requests made to a Server which is
+// shutting down, and for requests interrupted by shutdown.
+var ErrShutdown = errors.New("local: server is shut down")
+
+// Health is the lifecycle state of a Server.
+type Health int
+
+const (
+	// Idle servers have not been started. They execute requests, but are not
+	// reported as ready, for compatibility with servers used without
+	// lifecycle management.
+	Idle Health = iota
+	// Ready servers have been started, and accept new requests.
+	Ready
+	// Draining servers are shutting down. In-flight requests continue, but
+	// new top-level requests are refused.
+	Draining
+	// Stopped servers have shut down, and refuse new top-level requests.
+	Stopped
+)
+
+func (h Health) String() string {
+	switch h {
+	case Idle:
+		return "idle"
+	case Ready:
+		return "ready"
+	case Draining:
+		return "draining"
+	case Stopped:
+		return "stopped"
+	}
+	return "unknown"
+}
+
+// Flusher is implemented by stores which buffer writes.
+// Server.Shutdown flushes the store once in-flight requests have stopped.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Start marks the server as ready to accept requests.
+func (s *Server) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.health != Idle {
+		return errors.New("local: server already started")
+	}
+	s.health = Ready
+	return nil
+}
+
+// Health returns the lifecycle state of the server.
+func (s *Server) Health() Health {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.health
+}
+
+// Shutdown stops the server from accepting new top-level requests, and waits
+// for in-flight requests to complete. Calls issued by in-flight requests are
+// still accepted, other than detached calls, which fail with ErrShutdown.
+//
+// If the context is done first, in-flight requests are cancelled and
+// checkpointed: calls which completed are stored, but failures caused by the
+// shutdown are not, so the requests resume when next made. Shutdown waits for
+// cancelled requests to return, so endpoints which don't observe cancellation
+// delay it. The context's error is returned in this case.
+//
+// Once requests have stopped, the store is flushed if it is a Flusher.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.health == Stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	s.health = Draining
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		s.cancelAll(ErrShutdown)
+		<-done
+	}
+
+	if f, ok := s.store.(Flusher); ok {
+		err = errors.Join(err, f.Flush(context.WithoutCancel(ctx)))
+	}
+
+	s.mu.Lock()
+	s.health = Stopped
+	s.mu.Unlock()
+	return err
+}
+
+// enter registers a new top-level or detached request, unless the server is
+// shutting down.
+func (s *Server) enter() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.health >= Draining {
+		return ErrShutdown
+	}
+	s.inflight.Add(1)
+	return nil
+}
+
+// cancelAll cancels all executing requests.
+func (s *Server) cancelAll(cause error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, cancel := range s.active {
+		cancel(cause)
+	}
+}
@@ -0,0 +1,185 @@
+package local
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrListUnsupported is returned by Server.List if the store doesn't
+// implement Lister.
+var ErrListUnsupported = errors.New("local: store does not support listing")
+
+// ErrInvalidPageToken is returned when listing with a page token which was
+// not returned by a previous listing.
+var ErrInvalidPageToken = errors.New("local: invalid page token")
+
+// DefaultPageSize is the number of requests listed if the query doesn't set
+// a page size.
+const DefaultPageSize = 100
+
+// Status is the state of a request, used to filter listed requests.
+type Status int
+
+const (
+	// StatusAny matches requests in any state.
+	StatusAny Status = iota
+	// StatusRunning requests have not completed.
+	StatusRunning
+	// StatusSucceeded requests completed without an error.
+	StatusSucceeded
+	// StatusFailed requests completed with an error.
+	StatusFailed
+)
+
+func (st Status) String() string {
+	switch st {
+	case StatusAny:
+		return "any"
+	case StatusRunning:
+		return "running"
+	case StatusSucceeded:
+		return "succeeded"
+	case StatusFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// Status returns the state of the request.
+func (r *Request) Status() Status {
+	switch {
+	case !r.Done:
+		return StatusRunning
+	case r.Failed:
+		return StatusFailed
+	}
+	return StatusSucceeded
+}
+
+// Query selects the requests to list. Zero fields match any request.
+type Query struct {
+	Name   string            // Endpoint name.
+	Status Status            // State of requests.
+	Labels map[string]string // Labels which requests must have.
+
+	SubmittedAfter  time.Time // Requests submitted at or after this time.
+	SubmittedBefore time.Time // Requests submitted before this time.
+
+	TopLevel bool // Exclude requests issued by executions.
+
+	PageSize  int    // Maximum number of requests to list, or DefaultPageSize if zero.
+	PageToken string // Token returned by a previous listing, to continue it.
+}
+
+// Matches reports whether the request is selected by the query.
+func (q *Query) Matches(req *Request) bool {
+	switch {
+	case q.Name != "" && req.Name != q.Name:
+		return false
+	case q.Status != StatusAny && req.Status() != q.Status:
+		return false
+	case !q.SubmittedAfter.IsZero() && req.SubmittedAt.Before(q.SubmittedAfter):
+		return false
+	case !q.SubmittedBefore.IsZero() && !req.SubmittedAt.Before(q.SubmittedBefore):
+		return false
+	case q.TopLevel && req.ParentID != "":
+		return false
+	}
+	for k, v := range q.Labels {
+		if got, ok := req.Labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Lister is implemented by stores which can list requests.
+type Lister interface {
+	// List returns the requests selected by the query, most recently
+	// submitted first, along with a token to list the next page, which is
+	// empty if there are no more requests.
+	List(ctx context.Context, q Query) ([]*Request, string, error)
+}
+
+// List returns the requests selected by the query, most recently submitted
+// first, along with a token to list the next page, which is empty if there
+// are no more requests.
+//
+// Returns ErrListUnsupported if the store doesn't implement Lister.
+func (s *Server) List(ctx context.Context, q Query) ([]*Request, string, error) {
+	l, ok := s.store.(Lister)
+	if !ok {
+		return nil, "", ErrListUnsupported
+	}
+	return l.List(ctx, q)
+}
+
+// List implements Lister.
+func (ms *MemoryStore) List(_ context.Context, q Query) ([]*Request, string, error) {
+	var after *Request
+	if q.PageToken != "" {
+		var err error
+		if after, err = decodePageToken(q.PageToken); err != nil {
+			return nil, "", err
+		}
+	}
+	size := q.PageSize
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+
+	ms.mu.Lock()
+	var matches []*Request
+	for _, req := range ms.requests {
+		if q.Matches(req) && (after == nil || listOrder(after, req) < 0) {
+			matches = append(matches, req.clone())
+		}
+	}
+	ms.mu.Unlock()
+
+	slices.SortFunc(matches, listOrder)
+	if len(matches) <= size {
+		return matches, "", nil
+	}
+	matches = matches[:size]
+	return matches, encodePageToken(matches[size-1]), nil
+}
+
+// listOrder orders requests by descending submission time, then by ID.
+func listOrder(a, b *Request) int {
+	if c := b.SubmittedAt.Compare(a.SubmittedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+// encodePageToken returns a token which continues a listing after the
+// request.
+func encodePageToken(last *Request) string {
+	s := strconv.FormatInt(last.SubmittedAt.UnixNano(), 10) + "/" + last.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+// decodePageToken returns the position in the listing encoded by a token,
+// as a request which only has the fields used for ordering.
+func decodePageToken(token string) (*Request, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPageToken, err)
+	}
+	ts, id, ok := strings.Cut(string(data), "/")
+	if !ok {
+		return nil, ErrInvalidPageToken
+	}
+	nanos, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPageToken, err)
+	}
+	return &Request{ID: id, SubmittedAt: time.Unix(0, nanos)}, nil
+}
@@ -0,0 +1,76 @@
+package local
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/vgough/sequin"
+	"github.com/vgough/sequin/internal"
+)
+
+// LogLevels are the levels at which execution events are logged.
+type LogLevels struct {
+	Start    slog.Level // execution of a request started.
+	CacheHit slog.Level // call answered with stored results.
+	Finish   slog.Level // execution completed successfully.
+	Error    slog.Level // execution returned an error.
+}
+
+// DefaultLogLevels are the levels used unless set by WithLogLevels.
+var DefaultLogLevels = LogLevels{
+	Start:    slog.LevelDebug,
+	CacheHit: slog.LevelDebug,
+	Finish:   slog.LevelDebug,
+	Error:    slog.LevelError,
+}
+
+// WithLogger sets the logger used for top-level calls and the calls they
+// make. The default is the logger of the top-level caller's context, which
+// is slog.Default unless set by sequin.WithLogger.
+//
+// Each call is logged with attributes identifying the endpoint, request and
+// parent request, and the same logger is available to the endpoint by
+// sequin.Logger.
+func WithLogger(l *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = l
+	}
+}
+
+// WithLogLevels sets the levels at which execution events are logged.
+// The default is DefaultLogLevels.
+func WithLogLevels(levels LogLevels) Option {
+	return func(s *Server) {
+		s.logLevels = levels
+	}
+}
+
+// Attribute keys of the logger of a call.
+const (
+	LogEndpoint        = "endpoint"
+	LogRequestID       = "request_id"
+	LogParentRequestID = "parent_request_id"
+)
+
+// logBase holds the logger which loggers of calls are derived from, and is
+// inherited by the calls made during an execution.
+type logBase struct{ l *slog.Logger }
+
+var logBaseMD internal.MDKey[logBase]
+
+// withCallLogger returns a context holding the logger of a call.
+func (s *Server) withCallLogger(ctx context.Context, parent *execution, name, requestID string) context.Context {
+	base := logBaseMD.Get(ctx).l
+	if base == nil {
+		base = s.logger
+		if base == nil {
+			base = sequin.Logger(ctx)
+		}
+		ctx = logBaseMD.Set(ctx, logBase{base})
+	}
+	log := base.With(slog.String(LogEndpoint, name), slog.String(LogRequestID, requestID))
+	if parent != nil {
+		log = log.With(slog.String(LogParentRequestID, parent.req.ID))
+	}
+	return sequin.WithLogger(ctx, log)
+}
@@ -0,0 +1,119 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/vgough/sequin"
+	"github.com/vgough/sequin/registry"
+)
+
+func decodeLogs(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var rec map[string]any
+		require.NoError(t, dec.Decode(&rec))
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestServer_Logger(t *testing.T) {
+	reg := registry.New()
+	square := sequin.RegisterIn(reg, func(ctx context.Context, n int) (int, error) {
+		sequin.Logger(ctx).Info("squaring", "n", n)
+		if n < 0 {
+			return 0, errors.New("negative input")
+		}
+		return n * n, nil
+	}, sequin.Name("local.test.square"))
+	sumSquares := sequin.RegisterIn(reg, func(ctx context.Context, ns []int) (int, error) {
+		var total int
+		for _, n := range ns {
+			sq, err := square(ctx, n)
+			if err != nil {
+				return 0, err
+			}
+			total += sq
+		}
+		return total, nil
+	}, sequin.Name("local.test.sum-squares"))
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	s := NewServer(WithRegistry(reg))
+
+	// The caller's logger is used, along with its attributes.
+	ctx := sequin.WithLogger(context.Background(), logger.With("job", "nightly"))
+	ctx = sequin.WithRuntime(ctx, s)
+	_, err := sumSquares(ctx, []int{2, -1})
+	require.ErrorContains(t, err, "negative input")
+
+	records := decodeLogs(t, &buf)
+	var msgs []string
+	for _, rec := range records {
+		require.Equal(t, "nightly", rec["job"])
+		msgs = append(msgs, rec["level"].(string)+" "+rec["endpoint"].(string)+" "+rec["msg"].(string))
+	}
+	require.Equal(t, []string{
+		"DEBUG local.test.sum-squares sequin: execution started",
+		"DEBUG local.test.square sequin: execution started",
+		"INFO local.test.square squaring",
+		"DEBUG local.test.square sequin: execution finished",
+		"DEBUG local.test.square sequin: execution started",
+		"INFO local.test.square squaring",
+		"ERROR local.test.square sequin: execution failed",
+		"ERROR local.test.sum-squares sequin: execution failed",
+	}, msgs)
+
+	// Logs of steps identify the call which issued them.
+	parentID := records[0][LogRequestID]
+	require.NotEmpty(t, parentID)
+	require.NotContains(t, records[0], LogParentRequestID)
+	for _, rec := range records[1:7] {
+		require.Equal(t, parentID, rec[LogParentRequestID])
+		require.NotEqual(t, parentID, rec[LogRequestID])
+	}
+	require.Equal(t, "negative input", records[6]["error"])
+	require.EqualValues(t, 1, records[0]["attempt"])
+
+	// Repeated calls are answered with stored results.
+	_, err = sumSquares(ctx, []int{2, -1})
+	require.Error(t, err)
+	records = decodeLogs(t, &buf)
+	require.Len(t, records, 1)
+	require.Equal(t, "sequin: cache hit", records[0]["msg"])
+	require.Equal(t, parentID, records[0][LogRequestID])
+}
+
+func TestServer_LogLevels(t *testing.T) {
+	reg := registry.New()
+	double := sequin.RegisterIn(reg, func(_ context.Context, n int) (int, error) {
+		return n * 2, nil
+	}, sequin.Name("local.test.double"))
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	levels := DefaultLogLevels
+	levels.Finish = slog.LevelInfo
+	levels.CacheHit = slog.LevelWarn
+	ctx := sequin.WithRuntime(context.Background(),
+		NewServer(WithRegistry(reg), WithLogger(logger), WithLogLevels(levels)))
+
+	for range 2 {
+		_, err := double(ctx, 2)
+		require.NoError(t, err)
+	}
+	var msgs []string
+	for _, rec := range decodeLogs(t, &buf) {
+		msgs = append(msgs, rec["level"].(string)+" "+rec["msg"].(string))
+	}
+	require.Equal(t, []string{"INFO sequin: execution finished", "WARN sequin: cache hit"}, msgs)
+}
@@ -0,0 +1,46 @@
+package local
+
+import "time"
+
+// Metrics receives measurements of the calls handled by a Server.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ExecStarted is called when execution of a request starts.
+	ExecStarted(endpoint string)
+	// ExecFinished is called when execution of a request ends, with the time
+	// it took and the error returned by the endpoint, if any.
+	ExecFinished(endpoint string, elapsed time.Duration, err error)
+	// CacheHit is called when a call is answered with stored results, rather
+	// than by executing the request.
+	CacheHit(endpoint string)
+	// SharedWait is called when a call waits for an execution started by a
+	// concurrent call of the same request.
+	SharedWait(endpoint string)
+	// StoreSize is called with the number of stored requests after a request
+	// is stored, if the store implements Sizer.
+	StoreSize(n int)
+}
+
+// Sizer is implemented by stores which can report the number of requests
+// they hold.
+type Sizer interface {
+	Len() int
+}
+
+// WithMetrics sets the receiver of measurements of the server's calls.
+// See the metrics package for an implementation which exposes them to
+// Prometheus.
+func WithMetrics(m Metrics) Option {
+	return func(s *Server) {
+		s.metrics = m
+	}
+}
+
+// nopMetrics discards measurements.
+type nopMetrics struct{}
+
+func (nopMetrics) ExecStarted(string)                        {}
+func (nopMetrics) ExecFinished(string, time.Duration, error) {}
+func (nopMetrics) CacheHit(string)                           {}
+func (nopMetrics) SharedWait(string)                         {}
+func (nopMetrics) StoreSize(int)                             {}
@@ -0,0 +1,36 @@
+package local
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/vgough/sequin"
+	sequinv1 "github.com/vgough/sequin/gen/sequin/v1"
+	"github.com/vgough/sequin/registry"
+)
+
+// ExecOperation executes the endpoint named by a FuncOperation.
+//
+// This allows endpoints whose arguments and results are protobuf messages to
+// be called by clients using standard protobuf tooling. Arguments are unpacked
+// from the operation's Any messages, and results are returned packed as Any.
+// See registry.Endpoint.UnpackArgs for the requirements.
+func (s *Server) ExecOperation(ctx context.Context, op *sequinv1.FuncOperation) ([]*anypb.Any, error) {
+	ep := s.registry.GetEndpoint(op.GetName())
+	if ep == nil {
+		return nil, fmt.Errorf("%w: %s", registry.ErrEndpointNotFound, op.GetName())
+	}
+	args, err := ep.UnpackArgs(op.GetArgs())
+	if err != nil {
+		return nil, err
+	}
+	ep.SetContext(sequin.WithRuntime(ctx, s), args)
+
+	out := s.Exec(ep, args)
+	if err := ep.GetError(out); err != nil {
+		return nil, err
+	}
+	return ep.PackResults(out)
+}
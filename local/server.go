@@ -6,9 +6,15 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"errors"
+	"fmt"
+	"log/slog"
 	"reflect"
 	"sync"
+	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/singleflight"
 
 	"github.com/vgough/sequin"
@@ -17,104 +23,369 @@ import (
 )
 
 var encodingKey = []byte("sequin")
-var requestIDMD = internal.MDKey[string]{}
+
+// ErrNotRunning is returned when cancelling a request which is not executing.
+var ErrNotRunning = errors.New("local: request is not running")
+
+// ErrNotDone is returned when deleting a request which has not completed.
+var ErrNotDone = errors.New("local: request is not done")
+
+// ErrDeleteUnsupported is returned by Server.Delete if the store doesn't
+// implement Deleter.
+var ErrDeleteUnsupported = errors.New("local: store does not support deleting")
 
 type Server struct {
-	sf singleflight.Group
+	sf       singleflight.Group
+	store    Store
+	registry *registry.Registry
+	tracer   trace.Tracer
+	metrics  Metrics
 
-	mu sync.Mutex
+	logger    *slog.Logger
+	logLevels LogLevels
 
-	// maps from request id to current or recent requests.
-	cache map[string]*requestState
-}
+	nonDeterminism NonDeterminismPolicy
+
+	mu        sync.Mutex
+	active    map[string]context.CancelCauseFunc // executing requests, by ID.
+	calls     map[string]int                     // number of calls of unsettled requests, by ID.
+	cancelled map[string]error                   // causes of cancelling requests before executing.
 
-type requestState struct {
-	requestID string
-	results   [][]byte
+	health   Health
+	inflight sync.WaitGroup // top-level requests being executed.
+
+	// Crash simulation, used by CrashTest.
+	crashAfter int                // crash once this many requests complete.
+	completed  int                // number of requests completed.
+	crashed    bool               // set once the server has crashed.
+	onComplete func(req *Request) // called for each completed request.
 }
 
 var _ sequin.Runtime = &Server{}
+var _ sequin.Versioner = &Server{}
+var _ sequin.Sequencer = &Server{}
+var _ sequin.Canceler = &Server{}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithStore sets the store used to persist requests.
+// The default is a new MemoryStore.
+func WithStore(st Store) Option {
+	return func(s *Server) {
+		s.store = st
+	}
+}
 
-func NewServer() *Server {
-	return &Server{
-		cache: make(map[string]*requestState),
+// WithRegistry sets the registry used to resolve endpoints by name.
+// The default is registry.Default.
+func WithRegistry(r *registry.Registry) Option {
+	return func(s *Server) {
+		s.registry = r
 	}
 }
 
+func NewServer(opts ...Option) *Server {
+	s := &Server{logLevels: DefaultLogLevels}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.store == nil {
+		s.store = NewMemoryStore()
+	}
+	if s.registry == nil {
+		s.registry = registry.Default
+	}
+	if s.metrics == nil {
+		s.metrics = nopMetrics{}
+	}
+	if s.tracer == nil {
+		s.tracer = otel.GetTracerProvider().Tracer(TracerName)
+	}
+	return s
+}
+
 func (s *Server) Exec(ep *registry.Endpoint, args []reflect.Value) []reflect.Value {
+	issued := func(string) {}
+	if hook := issuedMD.Get(ep.GetContext(args)); hook != nil {
+		var once sync.Once
+		issued = func(requestID string) {
+			once.Do(func() { hook(requestID) })
+		}
+		defer issued("")
+	}
+	if s.isCrashed() {
+		return ep.MakeError(ErrCrashed)
+	}
+
 	// Marshal the arguments.
-	data, err := s.encodeValues(args)
+	data, err := ep.EncodeArgs(args)
 	if err != nil {
 		return ep.MakeError(err)
 	}
 
 	// create unique id from data.
 	ctx := ep.GetContext(args)
-	parentID := requestIDMD.Get(ctx)
-	if opt, ok := ep.Metadata[internal.GlobalIDGen]; ok {
-		if boolVal, ok := opt.(bool); ok && boolVal {
-			parentID = ""
-		}
+	parent := executionMD.Get(ctx)
+	parentID := scopeID(ctx, ep)
+	requestID := computeUniqueID(parentID, ep.Name, data)
+
+	// Requests made under previous names of the endpoint are also accepted.
+	var aliasIDs []string
+	for _, alias := range ep.Aliases {
+		aliasIDs = append(aliasIDs, computeUniqueID(parentID, alias, data))
+	}
+
+	ctx, span := s.tracer.Start(ctx, ep.Name, trace.WithAttributes(
+		AttrEndpoint.String(ep.Name), AttrRequestID.String(requestID)))
+	defer span.End()
+	if parent != nil {
+		span.SetAttributes(AttrParentRequestID.String(parent.req.ID))
 	}
+	ctx = s.withCallLogger(ctx, parent, ep.Name, requestID)
+	out := s.call(ctx, parent, requestID, aliasIDs, ep, data, issued)
+	if err := ep.GetError(out); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return out
+}
 
-	requestID := computeUniqueID(parentID, data)
+// call makes a call of an endpoint, returning its results. The issued
+// function is called once the call is recorded by the parent execution.
+func (s *Server) call(ctx context.Context, parent *execution, requestID string, aliasIDs []string,
+	ep *registry.Endpoint, data [][]byte, issued func(string)) []reflect.Value {
+	runCtx := ctx
+	settled := s.track(requestID)
+	policy, detached := sequin.DetachPolicy(ctx)
+	if parent != nil {
+		if (!detached || policy == sequin.ParentCloseWait) && parent.begin() {
+			untrack := settled
+			settled = func() {
+				untrack()
+				parent.pending.Done()
+			}
+		}
+		if detached && policy == sequin.ParentCloseWait {
+			// The execution waits for the call, even if cancelled.
+			runCtx = context.WithoutCancel(ctx)
+		}
+		err := parent.issue(ctx, Child{ID: requestID, Name: ep.Name, Detached: detached}, aliasIDs)
+		if err != nil {
+			settled()
+			return ep.MakeError(err)
+		}
+	}
+	issued(requestID)
 
-	results, err := s.run(ctx, requestID, ep, data)
+	results, err := s.run(runCtx, requestID, aliasIDs, ep, data, settled)
 	if err != nil {
 		return ep.MakeError(err)
 	}
 
 	// decode results
-	out, err := s.decodeValues(results, ep.OutputTypes)
+	out, err := internal.DecodeValues(results, ep.OutputTypes)
 	if err != nil {
 		return ep.MakeError(err)
 	}
 
+	if parent != nil && !detached && ep.GetError(out) == nil {
+		if err := parent.recordCompensation(ctx, ep, requestID, data); err != nil {
+			return ep.MakeError(err)
+		}
+	}
 	return out
 }
 
-func (s *Server) run(ctx context.Context, requestID string,
-	ep *registry.Endpoint, data [][]byte) ([][]byte, error) {
+// issuedHook is called once a call has been recorded. See OnIssued.
+type issuedHook func(requestID string)
+
+var issuedMD internal.MDKey[issuedHook]
+
+// OnIssued returns a context for making a call, such that issued is called
+// with the request ID once the call has been recorded by the execution which
+// made it, or with an empty ID if it failed first. See sequin.Sequencer.
+func (s *Server) OnIssued(ctx context.Context, issued func(string)) context.Context {
+	return issuedMD.Set(ctx, issued)
+}
+
+// Registry returns the registry used to resolve endpoints by name.
+func (s *Server) Registry() *registry.Registry {
+	return s.registry
+}
+
+// RequestID returns the ID of the request made by calling the endpoint with
+// the given arguments, which can be used to look up the request.
+func (s *Server) RequestID(ep *registry.Endpoint, args []reflect.Value) (string, error) {
+	data, err := ep.EncodeArgs(args)
+	if err != nil {
+		return "", err
+	}
+	return computeUniqueID(scopeID(ep.GetContext(args), ep), ep.Name, data), nil
+}
+
+// Lookup returns the stored state of a request.
+// Returns nil if the request is not found.
+func (s *Server) Lookup(ctx context.Context, requestID string) (*Request, error) {
+	return s.store.Get(ctx, requestID)
+}
+
+// Delete removes a completed request from the store. Calls which would have
+// been answered with its results execute it again.
+//
+// Returns ErrDeleteUnsupported if the store doesn't implement Deleter, and
+// ErrNotDone if the request has not completed. Deleting a request which is
+// not stored is not an error.
+func (s *Server) Delete(ctx context.Context, requestID string) error {
+	d, ok := s.store.(Deleter)
+	if !ok {
+		return ErrDeleteUnsupported
+	}
+	req, err := s.store.Get(ctx, requestID)
+	if err != nil || req == nil {
+		return err
+	}
+	if !req.Done {
+		return fmt.Errorf("%w: %s", ErrNotDone, requestID)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := d.Delete(ctx, requestID); err != nil {
+		return err
+	}
+	s.reportSize()
+	return nil
+}
 
+// run returns the results of a request, executing it unless it has
+// completed or is already executing. The settled function is called once the
+// request has completed or failed, which is after run returns if the context
+// is done first.
+func (s *Server) run(ctx context.Context, requestID string, aliasIDs []string,
+	ep *registry.Endpoint, data [][]byte, settled func()) ([][]byte, error) {
+
+	leader := false // set if this call executes the request.
 	res := s.sf.DoChan(requestID, func() (interface{}, error) {
-		s.mu.Lock()
-		state, ok := s.cache[requestID]
-		s.mu.Unlock()
-		if ok {
-			return state, nil
+		leader = true
+		// The request outlives the first caller, so is not bound to its
+		// cancellation.
+		ctx := context.WithoutCancel(ctx)
+		parent := executionMD.Get(ctx)
+		_, detached := sequin.DetachPolicy(ctx)
+		if parent == nil || detached {
+			// Detached requests may outlive the execution which issued them,
+			// so are waited for by Shutdown like top-level requests. Once
+			// draining, they fail with ErrShutdown, which isn't stored, so
+			// the issuing request makes the call again when resumed.
+			if err := s.enter(); err != nil {
+				return nil, err
+			}
+			defer s.inflight.Done()
 		}
-
-		results, err := s.exec(ep.Name, requestID, data)
+		req, err := s.store.Get(ctx, requestID)
 		if err != nil {
 			return nil, err
 		}
-		state = &requestState{requestID: requestID, results: results}
-		s.mu.Lock()
-		defer s.mu.Unlock()
-		s.cache[requestID] = state
+		if req != nil && req.Done {
+			s.cacheHit(ctx, ep.Name)
+			return runResult{results: req.Results, cacheHit: true}, nil
+		}
+		if req == nil {
+			results, err := s.lookupAliases(ctx, aliasIDs)
+			if err != nil {
+				return nil, err
+			} else if results != nil {
+				s.cacheHit(ctx, ep.Name)
+				return runResult{results: results, cacheHit: true}, nil
+			}
+			req = &Request{
+				ID:          requestID,
+				Name:        ep.Name,
+				Version:     ep.Version,
+				Labels:      sequin.Labels(ctx),
+				SubmittedAt: time.Now(),
+			}
+			switch {
+			case parent != nil && detached:
+				req.Originator = parent.req.ID
+			case parent != nil:
+				req.ParentID = parent.req.ID
+			}
+		} else if req.Version != ep.Version {
+			return nil, fmt.Errorf("%w: request %s was started by version %d of %s, current version is %d",
+				sequin.ErrIncompatibleVersion, requestID, req.Version, ep.Name, ep.Version)
+		}
+		req.Attempts++
+		req.StartedAt = time.Now()
+		rr := runResult{attempt: req.Attempts}
+		if err := s.save(ctx, req); err != nil {
+			return rr, err
+		}
 
-		return state, nil
+		results, err := s.exec(ctx, req, data)
+		if err != nil {
+			return rr, err
+		}
+		req.Done = true
+		req.Results = results
+		req.FinishedAt = time.Now()
+		if err := s.complete(ctx, req); err != nil {
+			return rr, err
+		}
+		rr.results = results
+		return rr, nil
 	})
 
 	select {
 	case <-ctx.Done():
+		go func() {
+			<-res
+			settled()
+		}()
 		return nil, ctx.Err()
 	case res := <-res:
+		settled()
+		// Each caller's span describes the shared outcome.
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(AttrShared.Bool(res.Shared))
+		if rr, ok := res.Val.(runResult); ok {
+			span.SetAttributes(AttrCacheHit.Bool(rr.cacheHit))
+			if !rr.cacheHit {
+				span.SetAttributes(AttrAttempt.Int(rr.attempt))
+			}
+		}
+		if res.Shared && !leader {
+			s.metrics.SharedWait(ep.Name)
+		}
 		if res.Err != nil {
 			return nil, res.Err
 		}
-		state := res.Val.(*requestState)
-		return state.results, nil
+		return res.Val.(runResult).results, nil
 	}
 }
 
-func (s *Server) exec(name string, requestID string, args [][]byte) ([][]byte, error) {
-	ep := registry.GetEndpoint(name)
+// runResult is the outcome of a request, shared by concurrent calls of it.
+type runResult struct {
+	results  [][]byte
+	cacheHit bool // answered with stored results.
+	attempt  int  // execution attempt, unless a cache hit.
+}
+
+// cacheHit records that a call was answered with stored results.
+func (s *Server) cacheHit(ctx context.Context, name string) {
+	s.metrics.CacheHit(name)
+	sequin.Logger(ctx).Log(ctx, s.logLevels.CacheHit, "sequin: cache hit")
+}
+
+// exec executes a request. The context is that of the caller, which is used
+// to find the issuing execution.
+func (s *Server) exec(caller context.Context, req *Request, args [][]byte) ([][]byte, error) {
+	ep := s.registry.GetEndpoint(req.Name)
 	if ep == nil {
-		return nil, errors.New("unknown function: " + name)
+		return nil, errors.New("unknown function: " + req.Name)
 	}
 
-	in, err := s.decodeValues(args, ep.InputTypes)
+	in, err := ep.DecodeArgs(args)
 	if err != nil {
 		return nil, err
 	}
@@ -122,59 +393,244 @@ func (s *Server) exec(name string, requestID string, args [][]byte) ([][]byte, e
 		in[ep.ContextIndex] = reflect.ValueOf(context.Background())
 	}
 
-	// TODO: chain to incoming context.
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Executions are cancelled along with the execution which issued them,
+	// unless they may be shared with other executions, or are detached with
+	// a policy other than ParentCloseCancel.
+	base := context.Background()
+	if parent := executionMD.Get(caller); parent != nil {
+		policy, detached := sequin.DetachPolicy(caller)
+		switch {
+		case bool(compensatingMD.Get(caller)):
+			// Compensations are made once the execution has failed, so are
+			// not cancelled along with it.
+		case detached:
+			if policy == sequin.ParentCloseCancel {
+				base = parent.ctx
+			}
+		case scopeID(caller, ep) != "":
+			base = parent.ctx
+		}
+	}
+	ctx, cancel := context.WithCancelCause(base)
+	defer cancel(nil)
+	// Calls made by the execution are traced as children of its call, and
+	// share its logger.
+	ctx = trace.ContextWithSpan(ctx, trace.SpanFromContext(caller))
+	ctx = logBaseMD.Set(ctx, logBaseMD.Get(caller))
+	log := sequin.Logger(caller)
+	ctx = sequin.WithLogger(ctx, log)
+	ctx = sequin.WithLabels(ctx, req.Labels)
+	s.setActive(req.ID, cancel)
+	defer s.setActive(req.ID, nil)
 
+	e := &execution{s: s, req: req, ctx: ctx}
 	ctx = sequin.WithRuntime(ctx, s)
-	ctx = requestIDMD.Set(ctx, requestID)
+	ctx = executionMD.Set(ctx, e)
 	ep.SetContext(ctx, in)
+	if err := s.registry.Resolve(ep, in); err != nil {
+		return nil, err
+	}
 
+	s.metrics.ExecStarted(ep.Name)
+	log.Log(ctx, s.logLevels.Start, "sequin: execution started", "attempt", req.Attempts)
+	start := time.Now()
 	out := ep.Exec(in)
-	return s.encodeValues(out)
+	if err := e.finish(); err != nil {
+		out = ep.MakeError(err)
+	}
+	elapsed, execErr := time.Since(start), ep.GetError(out)
+	if execErr != nil {
+		log.Log(ctx, s.logLevels.Error, "sequin: execution failed", "elapsed", elapsed, "error", execErr)
+	} else {
+		log.Log(ctx, s.logLevels.Finish, "sequin: execution finished", "elapsed", elapsed)
+	}
+	s.metrics.ExecFinished(ep.Name, elapsed, execErr)
+	req.Failed = execErr != nil
+	if errors.Is(execErr, ErrShutdown) || execErr != nil && errors.Is(context.Cause(ctx), ErrShutdown) {
+		// Interrupted by shutdown, or a detached call was refused while
+		// draining, so leave the request to be resumed rather than storing
+		// the failure.
+		return nil, ErrShutdown
+	}
+	if execErr != nil && len(req.Compensations) > 0 {
+		if err := s.compensate(ctx, e); err != nil {
+			return nil, err
+		}
+	}
+	return internal.EncodeValues(out)
 }
 
-func computeUniqueID(parentID string, data [][]byte) string {
-	hash := hmac.New(sha256.New, encodingKey)
-
-	var tmp [10]byte
-	hash.Write(internal.EncodeVarint(len(parentID), tmp))
-	hash.Write([]byte(parentID))
+// Cancel cancels the execution of a request, along with the calls it has
+// issued. The endpoint must observe cancellation of its context, and the
+// outcome of a cancelled request is stored as usual, typically as a
+// context.Canceled error.
+//
+// Requests which have been called, but have not started executing, are
+// cancelled once they start.
+//
+// Returns ErrNotRunning if the request is not being called or executing on
+// this server.
+func (s *Server) Cancel(requestID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cancel, ok := s.active[requestID]
+	switch {
+	case ok:
+		cancel(context.Canceled)
+	case s.calls[requestID] > 0:
+		if s.cancelled == nil {
+			s.cancelled = make(map[string]error)
+		}
+		s.cancelled[requestID] = context.Canceled
+	default:
+		return fmt.Errorf("%w: %s", ErrNotRunning, requestID)
+	}
+	return nil
+}
 
-	for _, d := range data {
-		hash.Write(internal.EncodeVarint(len(d), tmp))
-		hash.Write(d)
+// setActive records the cancel function of an executing request, or removes
+// it if nil.
+func (s *Server) setActive(requestID string, cancel context.CancelCauseFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel == nil {
+		delete(s.active, requestID)
+		return
+	}
+	if s.active == nil {
+		s.active = make(map[string]context.CancelCauseFunc)
+	}
+	s.active[requestID] = cancel
+	if cause, ok := s.cancelled[requestID]; ok {
+		delete(s.cancelled, requestID)
+		cancel(cause)
 	}
-	digest := hash.Sum(nil)
-	return base64.RawStdEncoding.EncodeToString(digest[:])
 }
 
-func (s *Server) encodeValues(values []reflect.Value) ([][]byte, error) {
-	data := make([][]byte, len(values))
-	for i, v := range values {
-		if v.Type() == registry.ContextType {
-			continue
+// track records a call of a request, returning a function to call once the
+// request has settled.
+func (s *Server) track(requestID string) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.calls == nil {
+		s.calls = make(map[string]int)
+	}
+	s.calls[requestID]++
+	return sync.OnceFunc(func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.calls[requestID]--; s.calls[requestID] == 0 {
+			delete(s.calls, requestID)
+			delete(s.cancelled, requestID)
 		}
-		d, err := internal.Encode(v)
+	})
+}
+
+// GetVersion returns the version recorded for a branch point within the
+// current execution. See sequin.GetVersion.
+func (s *Server) GetVersion(ctx context.Context, changeID string, min, max int) (int, error) {
+	e := executionMD.Get(ctx)
+	if e == nil {
+		// Top-level calls are not executed by the server, so there is no
+		// history to record the version in.
+		return max, nil
+	}
+	return e.version(ctx, changeID, min, max)
+}
+
+// save stores the state of an incomplete request.
+// The store is written without holding s.mu, so that executions don't wait
+// on each other's writes.
+func (s *Server) save(ctx context.Context, req *Request) error {
+	if s.isCrashed() {
+		return ErrCrashed
+	}
+	if err := s.store.Put(ctx, req); err != nil {
+		return err
+	}
+	s.reportSize()
+	return nil
+}
+
+// lookupAliases returns the results of a completed request stored under a
+// previous name of the endpoint, or nil if there is none.
+func (s *Server) lookupAliases(ctx context.Context, aliasIDs []string) ([][]byte, error) {
+	for _, id := range aliasIDs {
+		req, err := s.store.Get(ctx, id)
 		if err != nil {
 			return nil, err
 		}
-		data[i] = d
+		if req != nil && req.Done {
+			return req.Results, nil
+		}
 	}
-	return data, nil
+	return nil, nil
 }
 
-func (s *Server) decodeValues(data [][]byte, types []reflect.Type) ([]reflect.Value, error) {
-	values := make([]reflect.Value, len(data))
-	for i, d := range data {
-		if types[i] == registry.ContextType {
-			continue
-		}
-		val, err := internal.Decode(d, types[i])
-		if err != nil {
-			return nil, err
+// complete stores a completed request.
+func (s *Server) complete(ctx context.Context, req *Request) error {
+	if s.isCrashed() {
+		return ErrCrashed
+	}
+	if err := s.store.Put(ctx, req); err != nil {
+		return err
+	}
+	s.reportSize()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completed++
+	if s.onComplete != nil {
+		s.onComplete(req)
+	}
+	if s.crashAfter > 0 && s.completed >= s.crashAfter {
+		s.crashed = true
+		return ErrCrashed
+	}
+	return nil
+}
+
+// reportSize reports the number of stored requests, if known.
+func (s *Server) reportSize() {
+	if sz, ok := s.store.(Sizer); ok {
+		s.metrics.StoreSize(sz.Len())
+	}
+}
+
+func (s *Server) isCrashed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.crashed
+}
+
+// scopeID returns the ID which request IDs of calls of the endpoint made with
+// the context are scoped to. Detached calls are not scoped.
+func scopeID(ctx context.Context, ep *registry.Endpoint) string {
+	if opt, ok := ep.Metadata[internal.GlobalIDGen]; ok {
+		if boolVal, ok := opt.(bool); ok && boolVal {
+			return ""
 		}
-		values[i] = val
 	}
-	return values, nil
+	parent := executionMD.Get(ctx)
+	if _, detached := sequin.DetachPolicy(ctx); parent == nil || detached {
+		return ""
+	}
+	return parent.req.ID
+}
+
+func computeUniqueID(parentID string, name string, data [][]byte) string {
+	hash := hmac.New(sha256.New, encodingKey)
+
+	var tmp [10]byte
+	hash.Write(internal.EncodeVarint(len(parentID), tmp))
+	hash.Write([]byte(parentID))
+	hash.Write(internal.EncodeVarint(len(name), tmp))
+	hash.Write([]byte(name))
+
+	for _, d := range data {
+		hash.Write(internal.EncodeVarint(len(d), tmp))
+		hash.Write(d)
+	}
+	digest := hash.Sum(nil)
+	return base64.RawStdEncoding.EncodeToString(digest[:])
 }
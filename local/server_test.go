@@ -1,12 +1,27 @@
 package local
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
 	"github.com/vgough/sequin"
+	sequinv1 "github.com/vgough/sequin/gen/sequin/v1"
+	"github.com/vgough/sequin/internal"
+	"github.com/vgough/sequin/registry"
 )
 
 func TestServer(t *testing.T) {
@@ -16,6 +31,268 @@ func TestServer(t *testing.T) {
 	ok, err := IsEven(ctx, 0)
 	require.NoError(t, err)
 	require.True(t, ok)
+
+	_, err = IsEven(ctx, -1)
+	require.EqualError(t, err, "negative values not supported")
+}
+
+func TestServer_Registry(t *testing.T) {
+	reg := registry.New()
+	double := sequin.RegisterIn(reg, func(_ context.Context, in int) (int, error) {
+		return in * 2, nil
+	}, sequin.Name("local.test.double"))
+
+	s := NewServer(WithRegistry(reg))
+	ctx := sequin.WithRuntime(context.Background(), s)
+	out, err := double(ctx, 21)
+	require.NoError(t, err)
+	require.Equal(t, 42, out)
+
+	// Endpoints outside of the server's registry can't be executed.
+	_, err = IsEven(ctx, 2)
+	require.ErrorContains(t, err, "unknown function")
+}
+
+func TestServer_MethodBinding(t *testing.T) {
+	reg := registry.New()
+	counter := &Counter{}
+	counterAdd := sequin.RegisterIn(reg, (*Counter).Add,
+		sequin.Name("local.test.counter-add"), sequin.Bind(counter))
+	counterAddProvided := sequin.RegisterIn(reg, (*Counter).Add,
+		sequin.Name("local.test.counter-add-provided"),
+		sequin.BindProvider(func(context.Context) (*Counter, error) {
+			return counter, nil
+		}))
+	ctx := sequin.WithRuntime(context.Background(), NewServer(WithRegistry(reg)))
+
+	// The receiver isn't encodable, so must be bound rather than passed.
+	total, err := counterAdd(nil, ctx, 5)
+	require.NoError(t, err)
+	require.Equal(t, 5, total)
+	total, err = counterAdd(&Counter{}, ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, 7, total)
+	require.Equal(t, 7, counter.total)
+
+	total, err = counterAddProvided(nil, ctx, 3)
+	require.NoError(t, err)
+	require.Equal(t, 10, total)
+}
+
+func TestServer_Inject(t *testing.T) {
+	reg := registry.New()
+	db := &Database{rows: map[string]int{"a": 1, "b": 2}}
+	sequin.ProvideIn(reg, func(context.Context) (*Database, error) {
+		return db, nil
+	})
+	var calls int
+	lookup := sequin.RegisterIn(reg, func(_ context.Context, db *Database, key string) (int, error) {
+		calls++
+		return db.rows[key], nil
+	}, sequin.Name("local.test.lookup"), sequin.Inject[*Database]())
+
+	ctx := sequin.WithRuntime(context.Background(), NewServer(WithRegistry(reg)))
+	v, err := lookup(ctx, nil, "b")
+	require.NoError(t, err)
+	require.Equal(t, 2, v)
+
+	// Injected arguments are not part of the request ID.
+	v, err = lookup(ctx, &Database{}, "b")
+	require.NoError(t, err)
+	require.Equal(t, 2, v)
+	require.Equal(t, 1, calls)
+
+	// Without a provider, injected arguments can't be resolved.
+	unprovided := sequin.RegisterIn(reg, func(_ context.Context, _ *Counter) error {
+		return nil
+	}, sequin.Name("local.test.unprovided"), sequin.Inject[*Counter]())
+	err = unprovided(ctx, nil)
+	require.ErrorContains(t, err, "no provider registered")
+}
+
+func TestServer_Store(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := sequin.WithRuntime(context.Background(), NewServer(WithStore(store)))
+	before := sumCount.Load()
+	sum, err := SumEvens(ctx, []int{1, 2, 3, 4})
+	require.NoError(t, err)
+	require.Equal(t, 6, sum)
+	require.Equal(t, before+1, sumCount.Load())
+
+	// A new server over the same store returns the stored result.
+	ctx = sequin.WithRuntime(context.Background(), NewServer(WithStore(store)))
+	sum, err = SumEvens(ctx, []int{1, 2, 3, 4})
+	require.NoError(t, err)
+	require.Equal(t, 6, sum)
+	require.Equal(t, before+1, sumCount.Load())
+}
+
+func TestCrashTest(t *testing.T) {
+	CrashTest(t, func(ctx context.Context) (int, error) {
+		return SumEvens(ctx, []int{1, 2, 3, 4, -5})
+	})
+	CrashTest(t, func(ctx context.Context) (int, error) {
+		return SumEvens(ctx, []int{6, 7, 8})
+	})
+}
+
+func TestCrashTest_NonDeterministic(t *testing.T) {
+	tb := &captureTB{TB: t}
+	CrashTest(tb, func(ctx context.Context) (int, error) {
+		return SumEvens(ctx, []int{int(sumCount.Load()), 2})
+	})
+	require.NotEmpty(t, tb.errors)
+}
+
+func TestServer_NonDeterminism(t *testing.T) {
+	// Record a partial history by crashing after two calls complete.
+	store := NewMemoryStore()
+	s := NewServer(WithStore(store))
+	s.crashAfter = 2
+	ctx := sequin.WithRuntime(context.Background(), s)
+	_, err := SumEvens(ctx, []int{10, 11, 12})
+	require.ErrorIs(t, err, ErrCrashed)
+
+	// Replaying the same calls matches history.
+	s = NewServer(WithStore(store), WithNonDeterminism(FailNonDeterminism))
+	ctx = sequin.WithRuntime(context.Background(), s)
+	sum, err := SumEvens(ctx, []int{10, 11, 12})
+	require.NoError(t, err)
+	require.Equal(t, 22, sum)
+}
+
+func TestServer_NonDeterminismDetected(t *testing.T) {
+	store := NewMemoryStore()
+	s := NewServer(WithStore(store))
+	s.crashAfter = 2
+	ctx := sequin.WithRuntime(context.Background(), s)
+	_, err := CheckValues(ctx, "diverge")
+	require.ErrorIs(t, err, ErrCrashed)
+
+	checkValues = []int{20, 22, 23}
+	defer func() { checkValues = []int{20, 21, 22} }()
+
+	// Warnings allow execution to continue, and identify the request.
+	var logs bytes.Buffer
+	s = NewServer(WithStore(store), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	ctx = sequin.WithRuntime(context.Background(), s)
+	_, err = CheckValues(ctx, "diverge")
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), "non-deterministic workflow")
+	require.Contains(t, logs.String(), LogRequestID+"=")
+
+	// The history was replaced by the warned execution, so start over.
+	store = NewMemoryStore()
+	s = NewServer(WithStore(store))
+	s.crashAfter = 2
+	ctx = sequin.WithRuntime(context.Background(), s)
+	checkValues = []int{20, 21, 22}
+	_, err = CheckValues(ctx, "diverge")
+	require.ErrorIs(t, err, ErrCrashed)
+
+	checkValues = []int{20, 22, 23}
+	s = NewServer(WithStore(store), WithNonDeterminism(FailNonDeterminism))
+	ctx = sequin.WithRuntime(context.Background(), s)
+	_, err = CheckValues(ctx, "diverge")
+	require.ErrorContains(t, err, ErrNonDeterministic.Error())
+	require.Contains(t, err.Error(), "call 2 to")
+	require.Contains(t, err.Error(), "-   2 ")
+	require.Contains(t, err.Error(), "+   2 ")
+}
+
+func TestServer_NonDeterminismSkipped(t *testing.T) {
+	store := NewMemoryStore()
+	s := NewServer(WithStore(store))
+	s.crashAfter = 3
+	ctx := sequin.WithRuntime(context.Background(), s)
+	_, err := CheckValues(ctx, "skip")
+	require.ErrorIs(t, err, ErrCrashed)
+
+	checkValues = []int{20}
+	defer func() { checkValues = []int{20, 21, 22} }()
+
+	s = NewServer(WithStore(store), WithNonDeterminism(FailNonDeterminism))
+	ctx = sequin.WithRuntime(context.Background(), s)
+	_, err = CheckValues(ctx, "skip")
+	require.ErrorContains(t, err, ErrNonDeterministic.Error())
+	require.Contains(t, err.Error(), "2 calls in history were skipped")
+}
+
+func TestServer_Version(t *testing.T) {
+	// Record history from before the branch point was added.
+	store := NewMemoryStore()
+	s := NewServer(WithStore(store))
+	s.crashAfter = 2
+	ctx := sequin.WithRuntime(context.Background(), s)
+	_, err := Upgraded(ctx, "old")
+	require.ErrorIs(t, err, ErrCrashed)
+
+	useBranch = true
+	defer func() { useBranch = false }()
+
+	// Executions which predate the branch point get the default version.
+	ctx = sequin.WithRuntime(context.Background(), NewServer(WithStore(store)))
+	v, err := Upgraded(ctx, "old")
+	require.NoError(t, err)
+	require.Equal(t, sequin.DefaultVersion, v)
+
+	// New executions get the latest version.
+	ctx = sequin.WithRuntime(context.Background(), NewServer())
+	v, err = Upgraded(ctx, "new")
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+}
+
+func TestServer_IncompatibleVersion(t *testing.T) {
+	store := NewMemoryStore()
+	s := NewServer(WithStore(store))
+	s.crashAfter = 1
+	ctx := sequin.WithRuntime(context.Background(), s)
+	_, err := Upgraded(ctx, "incompatible")
+	require.ErrorIs(t, err, ErrCrashed)
+
+	ep := registry.GetEndpoint("github.com/vgough/sequin/local.upgraded")
+	require.NotNil(t, ep)
+	ep.Version++
+	defer func() { ep.Version-- }()
+
+	ctx = sequin.WithRuntime(context.Background(), NewServer(WithStore(store)))
+	_, err = Upgraded(ctx, "incompatible")
+	require.ErrorIs(t, err, sequin.ErrIncompatibleVersion)
+}
+
+func TestServer_Alias(t *testing.T) {
+	reg := registry.New()
+	var executed atomic.Int32
+	isEvenRenamed := sequin.RegisterIn(reg, func(_ context.Context, in int) (bool, error) {
+		executed.Add(1)
+		return in%2 == 0, nil
+	}, sequin.Name("local.test.is-even"), sequin.Alias("local.test.parity"))
+
+	// Store a result under the previous name of the endpoint.
+	ctx := context.Background()
+	data, err := internal.EncodeValues([]reflect.Value{reflect.ValueOf(&ctx).Elem(), reflect.ValueOf(7)})
+	require.NoError(t, err)
+	results, err := internal.EncodeValues([]reflect.Value{reflect.ValueOf(false), reflect.Zero(registry.ErrorType)})
+	require.NoError(t, err)
+	store := NewMemoryStore()
+	require.NoError(t, store.Put(ctx, &Request{
+		ID:      computeUniqueID("", "local.test.parity", data),
+		Name:    "local.test.parity",
+		Done:    true,
+		Results: results,
+	}))
+
+	ctx = sequin.WithRuntime(ctx, NewServer(WithRegistry(reg), WithStore(store)))
+	even, err := isEvenRenamed(ctx, 7)
+	require.NoError(t, err)
+	require.False(t, even)
+	require.Zero(t, executed.Load())
+
+	even, err = isEvenRenamed(ctx, 8)
+	require.NoError(t, err)
+	require.True(t, even)
+	require.EqualValues(t, 1, executed.Load())
 }
 
 var IsEven = sequin.Register(isEven)
@@ -29,3 +306,878 @@ func isEven(ctx context.Context, in int) (bool, error) {
 	}
 	return false, nil
 }
+
+var sumCount atomic.Int32
+
+var SumEvens = sequin.Register(sumEvens)
+
+// sumEvens adds the even values, skipping values which can't be checked.
+func sumEvens(ctx context.Context, values []int) (int, error) {
+	sumCount.Add(1)
+	var sum int
+	for _, v := range values {
+		if ok, _ := IsEven(ctx, v); ok {
+			sum += v
+		}
+	}
+	return sum, nil
+}
+
+var checkValues = []int{20, 21, 22}
+
+var CheckValues = sequin.Register(checkValuesFn)
+
+// checkValuesFn checks the values in checkValues, which tests modify to
+// simulate code changes. The label separates executions between tests.
+func checkValuesFn(ctx context.Context, _ string) (int, error) {
+	var count int
+	for _, v := range checkValues {
+		ok, err := IsEven(ctx, v)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// useBranch simulates adding a branch point to upgraded.
+var useBranch bool
+
+var Upgraded = sequin.Register(upgraded)
+
+func upgraded(ctx context.Context, _ string) (int, error) {
+	if _, err := IsEven(ctx, 30); err != nil {
+		return 0, err
+	}
+
+	v := sequin.DefaultVersion
+	if useBranch {
+		var err error
+		v, err = sequin.GetVersion(ctx, "extra-check", sequin.DefaultVersion, 1)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := IsEven(ctx, 31); err != nil {
+		return 0, err
+	}
+	if v >= 1 {
+		if _, err := IsEven(ctx, 32); err != nil {
+			return 0, err
+		}
+	}
+	return v, nil
+}
+
+// Counter has no exported state, so can't be encoded.
+type Counter struct {
+	mu    sync.Mutex
+	total int
+}
+
+func (c *Counter) Add(_ context.Context, n int) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.total += n
+	return c.total, nil
+}
+
+// Database has no exported state, so can't be encoded.
+type Database struct {
+	rows map[string]int
+}
+
+// captureTB records test errors rather than failing the test.
+type captureTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *captureTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func TestServer_ExecOperation(t *testing.T) {
+	reg := registry.New()
+	sequin.RegisterIn(reg, func(_ context.Context, s *wrapperspb.StringValue) (*wrapperspb.Int64Value, error) {
+		if s.GetValue() == "" {
+			return nil, errors.New("empty string")
+		}
+		return wrapperspb.Int64(int64(len(s.GetValue()))), nil
+	}, sequin.Name("local.test.length"))
+	srv := NewServer(WithRegistry(reg))
+
+	op := func(name string, args ...proto.Message) *sequinv1.FuncOperation {
+		op := &sequinv1.FuncOperation{Name: name}
+		for _, a := range args {
+			p, err := anypb.New(a)
+			require.NoError(t, err)
+			op.Args = append(op.Args, p)
+		}
+		return op
+	}
+
+	ctx := context.Background()
+	out, err := srv.ExecOperation(ctx, op("local.test.length", wrapperspb.String("hello")))
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	var n wrapperspb.Int64Value
+	require.NoError(t, out[0].UnmarshalTo(&n))
+	require.EqualValues(t, 5, n.GetValue())
+
+	_, err = srv.ExecOperation(ctx, op("local.test.length", wrapperspb.String("")))
+	require.ErrorContains(t, err, "empty string")
+
+	_, err = srv.ExecOperation(ctx, op("local.test.length", wrapperspb.Int32(5)))
+	require.ErrorContains(t, err, "must be google.protobuf.StringValue")
+
+	_, err = srv.ExecOperation(ctx, op("local.test.length"))
+	require.ErrorContains(t, err, "expects 1 arguments, got 0")
+
+	_, err = srv.ExecOperation(ctx, op("local.test.unknown"))
+	require.ErrorIs(t, err, registry.ErrEndpointNotFound)
+}
+
+func TestServer_Cancel(t *testing.T) {
+	reg := registry.New()
+	started := make(chan struct{})
+	child := sequin.RegisterIn(reg, func(ctx context.Context, n int) (int, error) {
+		close(started)
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, sequin.Name("local.test.blocked"))
+	parent := sequin.RegisterIn(reg, func(ctx context.Context, n int) (int, error) {
+		return child(ctx, n)
+	}, sequin.Name("local.test.parent"))
+
+	s := NewServer(WithRegistry(reg))
+	ep := reg.GetEndpoint("local.test.parent")
+	ctx := sequin.WithRuntime(context.Background(), s)
+	args := []reflect.Value{{}, reflect.ValueOf(1)}
+	ep.SetContext(ctx, args)
+	id, err := s.RequestID(ep, args)
+	require.NoError(t, err)
+	require.ErrorIs(t, s.Cancel(id), ErrNotRunning)
+
+	done := make(chan error)
+	go func() {
+		_, err := parent(ctx, 1)
+		done <- err
+	}()
+	<-started
+
+	// Cancelling the parent also cancels the child it issued.
+	require.NoError(t, s.Cancel(id))
+	require.ErrorContains(t, <-done, context.Canceled.Error())
+
+	req, err := s.Lookup(ctx, id)
+	require.NoError(t, err)
+	require.True(t, req.Done)
+	require.Len(t, req.Children, 1)
+	require.ErrorIs(t, s.Cancel(id), ErrNotRunning)
+}
+
+type flushStore struct {
+	*MemoryStore
+	flushed int
+}
+
+func (s *flushStore) Flush(context.Context) error {
+	s.flushed++
+	return nil
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	reg := registry.New()
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	step := sequin.RegisterIn(reg, func(_ context.Context, n int) (int, error) {
+		return n + 1, nil
+	}, sequin.Name("local.test.step"))
+	slow := sequin.RegisterIn(reg, func(ctx context.Context, n int) (int, error) {
+		started <- struct{}{}
+		<-release
+		// Calls issued by in-flight requests are accepted while draining.
+		return step(ctx, n)
+	}, sequin.Name("local.test.slow"))
+
+	store := &flushStore{MemoryStore: NewMemoryStore()}
+	s := NewServer(WithRegistry(reg), WithStore(store))
+	require.Equal(t, Idle, s.Health())
+	require.NoError(t, s.Start())
+	require.Equal(t, Ready, s.Health())
+	require.Error(t, s.Start())
+
+	ctx := sequin.WithRuntime(context.Background(), s)
+	result := make(chan int)
+	go func() {
+		v, err := slow(ctx, 1)
+		require.NoError(t, err)
+		result <- v
+	}()
+	<-started
+
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- s.Shutdown(context.Background())
+	}()
+	require.Eventually(t, func() bool { return s.Health() == Draining }, time.Second, time.Millisecond)
+
+	// New top-level requests are refused.
+	_, err := step(ctx, 5)
+	require.ErrorIs(t, err, ErrShutdown)
+
+	close(release)
+	require.Equal(t, 2, <-result)
+	require.NoError(t, <-shutdown)
+	require.Equal(t, Stopped, s.Health())
+	require.Equal(t, 1, store.flushed)
+	require.NoError(t, s.Shutdown(context.Background()))
+}
+
+func TestServer_ShutdownCheckpoint(t *testing.T) {
+	reg := registry.New()
+	var steps atomic.Int32
+	step := sequin.RegisterIn(reg, func(_ context.Context, n int) (int, error) {
+		steps.Add(1)
+		return n + 1, nil
+	}, sequin.Name("local.test.step"))
+	block := true
+	started := make(chan struct{}, 1)
+	workflow := sequin.RegisterIn(reg, func(ctx context.Context, n int) (int, error) {
+		v, err := step(ctx, n)
+		if err != nil {
+			return 0, err
+		}
+		if block {
+			started <- struct{}{}
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return step(ctx, v)
+	}, sequin.Name("local.test.workflow"))
+
+	store := NewMemoryStore()
+	s := NewServer(WithRegistry(reg), WithStore(store))
+	ctx := sequin.WithRuntime(context.Background(), s)
+	done := make(chan error)
+	go func() {
+		_, err := workflow(ctx, 1)
+		done <- err
+	}()
+	<-started
+
+	// The deadline passes, so the workflow is interrupted.
+	expired, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, s.Shutdown(expired), context.Canceled)
+	require.ErrorIs(t, <-done, ErrShutdown)
+
+	// A new server resumes the workflow, without repeating the first step.
+	block = false
+	ctx = sequin.WithRuntime(context.Background(), NewServer(WithRegistry(reg), WithStore(store)))
+	v, err := workflow(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 3, v)
+	require.EqualValues(t, 2, steps.Load())
+}
+
+func TestServer_Labels(t *testing.T) {
+	reg := registry.New()
+	var seen []map[string]string
+	step := sequin.RegisterIn(reg, func(ctx context.Context, n int) (int, error) {
+		seen = append(seen, sequin.Labels(ctx))
+		return n + 1, nil
+	}, sequin.Name("local.test.step"))
+	workflow := sequin.RegisterIn(reg, func(ctx context.Context, n int) (int, error) {
+		seen = append(seen, sequin.Labels(ctx))
+		v, err := step(ctx, n)
+		if err != nil {
+			return 0, err
+		}
+		return step(sequin.WithLabels(ctx, map[string]string{"phase": "second"}), v)
+	}, sequin.Name("local.test.workflow"))
+
+	s := NewServer(WithRegistry(reg))
+	ctx := sequin.WithRuntime(context.Background(), s)
+	ctx = sequin.WithLabels(ctx, map[string]string{"run": "nightly"})
+	_, err := workflow(ctx, 1)
+	require.NoError(t, err)
+
+	// Calls inherit the labels of the step which made them.
+	require.Equal(t, []map[string]string{
+		{"run": "nightly"},
+		{"run": "nightly"},
+		{"run": "nightly", "phase": "second"},
+	}, seen)
+
+	// Labels are stored with each request.
+	ep := reg.GetEndpoint("local.test.workflow")
+	args := []reflect.Value{{}, reflect.ValueOf(1)}
+	ep.SetContext(ctx, args)
+	id, err := s.RequestID(ep, args)
+	require.NoError(t, err)
+	req, err := s.Lookup(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"run": "nightly"}, req.Labels)
+	require.Len(t, req.Children, 2)
+	child, err := s.Lookup(ctx, req.Children[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"run": "nightly", "phase": "second"}, child.Labels)
+}
+
+func TestServer_List(t *testing.T) {
+	reg := registry.New()
+	square := sequin.RegisterIn(reg, func(_ context.Context, n int) (int, error) {
+		if n < 0 {
+			return 0, errors.New("negative input")
+		}
+		return n * n, nil
+	}, sequin.Name("local.test.square"))
+	sumSquares := sequin.RegisterIn(reg, func(ctx context.Context, ns []int) (int, error) {
+		var total int
+		for _, n := range ns {
+			sq, err := square(ctx, n)
+			if err != nil {
+				return 0, err
+			}
+			total += sq
+		}
+		return total, nil
+	}, sequin.Name("local.test.sum-squares"))
+
+	s := NewServer(WithRegistry(reg))
+	ctx := sequin.WithRuntime(context.Background(), s)
+	start := time.Now()
+	_, err := sumSquares(sequin.WithLabels(ctx, map[string]string{"run": "a"}), []int{1, 2})
+	require.NoError(t, err)
+	mid := time.Now()
+	_, err = sumSquares(sequin.WithLabels(ctx, map[string]string{"run": "b"}), []int{3, -1})
+	require.Error(t, err)
+
+	list := func(q Query) []string {
+		reqs, next, err := s.List(ctx, q)
+		require.NoError(t, err)
+		require.Empty(t, next)
+		var out []string
+		for _, req := range reqs {
+			out = append(out, fmt.Sprintf("%s %v %s", req.Name, req.Status(), req.Labels["run"]))
+		}
+		return out
+	}
+
+	// Most recently submitted first.
+	require.Equal(t, []string{
+		"local.test.square failed b",
+		"local.test.square succeeded b",
+		"local.test.sum-squares failed b",
+		"local.test.square succeeded a",
+		"local.test.square succeeded a",
+		"local.test.sum-squares succeeded a",
+	}, list(Query{}))
+	require.Equal(t, []string{"local.test.sum-squares failed b", "local.test.sum-squares succeeded a"},
+		list(Query{TopLevel: true}))
+	require.Equal(t, []string{"local.test.sum-squares failed b"},
+		list(Query{Name: "local.test.sum-squares", Status: StatusFailed}))
+	require.Equal(t, []string{"local.test.square failed b"},
+		list(Query{Name: "local.test.square", Status: StatusFailed, Labels: map[string]string{"run": "b"}}))
+	require.Len(t, list(Query{Labels: map[string]string{"run": "a"}}), 3)
+	require.Len(t, list(Query{SubmittedAfter: mid}), 3)
+	require.Len(t, list(Query{SubmittedAfter: start, SubmittedBefore: mid}), 3)
+	require.Empty(t, list(Query{Status: StatusRunning}))
+
+	// Pages continue where the previous one ended.
+	all := list(Query{})
+	var paged []string
+	q := Query{PageSize: 4}
+	for {
+		reqs, next, err := s.List(ctx, q)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(reqs), 4)
+		for _, req := range reqs {
+			paged = append(paged, fmt.Sprintf("%s %v %s", req.Name, req.Status(), req.Labels["run"]))
+		}
+		if next == "" {
+			break
+		}
+		q.PageToken = next
+	}
+	require.Equal(t, all, paged)
+
+	_, _, err = s.List(ctx, Query{PageToken: "invalid"})
+	require.ErrorIs(t, err, ErrInvalidPageToken)
+	_, _, err = NewServer(WithStore(struct{ Store }{NewMemoryStore()})).List(ctx, Query{})
+	require.ErrorIs(t, err, ErrListUnsupported)
+}
+
+func TestServer_Map(t *testing.T) {
+	reg := registry.New()
+	var mu sync.Mutex
+	executed := map[int]int{}
+	block := true
+	blocked := make(chan struct{})
+	step := sequin.RegisterIn(reg, func(ctx context.Context, n int) (int, error) {
+		mu.Lock()
+		executed[n]++
+		mu.Unlock()
+		if n == 3 && block {
+			close(blocked)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		// Later inputs complete first.
+		time.Sleep(time.Duration(10-n) * time.Millisecond)
+		return n * 10, nil
+	}, sequin.Name("local.test.step"))
+	workflow := sequin.RegisterIn(reg, func(ctx context.Context, ns []int) ([]int, error) {
+		return sequin.Map(ctx, ns, step, sequin.Parallelism(3))
+	}, sequin.Name("local.test.workflow"))
+
+	store := NewMemoryStore()
+	s := NewServer(WithRegistry(reg), WithStore(store))
+	ctx := sequin.WithRuntime(context.Background(), s)
+	inputs := []int{1, 2, 3, 4, 5, 6}
+	done := make(chan error)
+	go func() {
+		_, err := workflow(ctx, inputs)
+		done <- err
+	}()
+
+	// Interrupt the workflow while one item is unfinished.
+	<-blocked
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(executed) == len(inputs)
+	}, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	expired, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, s.Shutdown(expired), context.Canceled)
+	require.ErrorIs(t, <-done, ErrShutdown)
+
+	// Calls are recorded in the order of the inputs, so the resumed workflow
+	// is deterministic, and only repeats the unfinished item.
+	block = false
+	s = NewServer(WithRegistry(reg), WithStore(store), WithNonDeterminism(FailNonDeterminism))
+	ctx = sequin.WithRuntime(context.Background(), s)
+	out, err := workflow(ctx, inputs)
+	require.NoError(t, err)
+	require.Equal(t, []int{10, 20, 30, 40, 50, 60}, out)
+	require.Equal(t, map[int]int{1: 1, 2: 1, 3: 2, 4: 1, 5: 1, 6: 1}, executed)
+
+	ep := reg.GetEndpoint("local.test.workflow")
+	args := []reflect.Value{{}, reflect.ValueOf(inputs)}
+	ep.SetContext(ctx, args)
+	id, err := s.RequestID(ep, args)
+	require.NoError(t, err)
+	req, err := s.Lookup(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, req.Children, len(inputs))
+	for i, child := range req.Children {
+		c, err := s.Lookup(ctx, child.ID)
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprint(inputs[i]*10), fmt.Sprint(decodeInt(t, c.Results[0])))
+	}
+}
+
+func decodeInt(t *testing.T, data []byte) int {
+	out, err := internal.DecodeValues([][]byte{data}, []reflect.Type{reflect.TypeOf(0)})
+	require.NoError(t, err)
+	return int(out[0].Int())
+}
+
+func TestServer_Async(t *testing.T) {
+	reg := registry.New()
+	var mu sync.Mutex
+	executed := map[int]int{}
+	var completed atomic.Int32
+	block := true
+	blocked := make(chan struct{})
+	step := sequin.RegisterIn(reg, func(ctx context.Context, n int) (int, error) {
+		mu.Lock()
+		executed[n]++
+		mu.Unlock()
+		if n == 3 && block {
+			close(blocked)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		time.Sleep(time.Duration(10-n) * time.Millisecond)
+		completed.Add(1)
+		return n * 10, nil
+	}, sequin.Name("local.test.step"))
+	workflow := sequin.RegisterIn(reg, func(ctx context.Context, ns []int) (int, error) {
+		var futures []*sequin.Future[int]
+		for _, n := range ns {
+			futures = append(futures, sequin.Async(ctx, func(ctx context.Context) (int, error) {
+				return step(ctx, n)
+			}))
+		}
+		// This future is never waited on.
+		sequin.Async(ctx, func(ctx context.Context) (int, error) {
+			return step(ctx, 9)
+		})
+		var sum int
+		for _, f := range slices.Backward(futures) {
+			require.NotEmpty(t, f.RequestID())
+			v, err := f.Wait(ctx)
+			if err != nil {
+				return 0, err
+			}
+			sum += v
+		}
+		return sum, nil
+	}, sequin.Name("local.test.workflow"))
+
+	store := NewMemoryStore()
+	s := NewServer(WithRegistry(reg), WithStore(store))
+	ctx := sequin.WithRuntime(context.Background(), s)
+	inputs := []int{1, 2, 3, 4}
+	done := make(chan error)
+	go func() {
+		_, err := workflow(ctx, inputs)
+		done <- err
+	}()
+
+	// Interrupt the workflow once only one future is outstanding.
+	<-blocked
+	require.Eventually(t, func() bool {
+		return completed.Load() == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	expired, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, s.Shutdown(expired), context.Canceled)
+	require.ErrorIs(t, <-done, ErrShutdown)
+
+	// Futures of completed calls resolve with stored results when resumed.
+	block = false
+	s = NewServer(WithRegistry(reg), WithStore(store), WithNonDeterminism(FailNonDeterminism))
+	ctx = sequin.WithRuntime(context.Background(), s)
+	sum, err := workflow(ctx, inputs)
+	require.NoError(t, err)
+	require.Equal(t, 100, sum)
+	require.Equal(t, map[int]int{1: 1, 2: 1, 3: 2, 4: 1, 9: 1}, executed)
+
+	// Calls are recorded in the order futures were started, and the call
+	// which was not waited on completed along with the workflow.
+	ep := reg.GetEndpoint("local.test.workflow")
+	args := []reflect.Value{{}, reflect.ValueOf(inputs)}
+	ep.SetContext(ctx, args)
+	id, err := s.RequestID(ep, args)
+	require.NoError(t, err)
+	req, err := s.Lookup(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, req.Children, 5)
+	for i, n := range append(inputs, 9) {
+		c, err := s.Lookup(ctx, req.Children[i].ID)
+		require.NoError(t, err)
+		require.True(t, c.Done)
+		require.Equal(t, n*10, decodeInt(t, c.Results[0]))
+	}
+}
+
+func TestServer_AsyncCancel(t *testing.T) {
+	reg := registry.New()
+	started := make(chan struct{})
+	wait := sequin.RegisterIn(reg, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}, sequin.Name("local.test.wait"))
+	workflow := sequin.RegisterIn(reg, func(ctx context.Context) (string, error) {
+		f := sequin.Async(ctx, func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, wait(ctx)
+		})
+		<-started
+		f.Cancel()
+		_, err := f.Wait(ctx)
+		if !errors.Is(err, context.Canceled) {
+			return "", fmt.Errorf("unexpected error: %v", err)
+		}
+		return f.RequestID(), nil
+	}, sequin.Name("local.test.workflow"))
+
+	s := NewServer(WithRegistry(reg))
+	ctx := sequin.WithRuntime(context.Background(), s)
+	id, err := workflow(ctx)
+	require.NoError(t, err)
+
+	// The cancelled execution is stored before the workflow completes.
+	req, err := s.Lookup(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, req.Status())
+}
+
+func TestServer_Detach(t *testing.T) {
+	reg := registry.New()
+	release := map[string]chan struct{}{
+		"abandon": make(chan struct{}),
+		"cancel":  make(chan struct{}),
+		"wait":    make(chan struct{}),
+	}
+	child := sequin.RegisterIn(reg, func(ctx context.Context, name string) (string, error) {
+		select {
+		case <-release[name]:
+			return name, nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}, sequin.Name("local.test.child"))
+	workflow := sequin.RegisterIn(reg, func(ctx context.Context, policy sequin.ParentClosePolicy) (string, error) {
+		f := sequin.Async(sequin.Detach(ctx, policy), func(ctx context.Context) (string, error) {
+			return child(ctx, policy.String())
+		})
+		return f.RequestID(), nil
+	}, sequin.Name("local.test.workflow"))
+
+	s := NewServer(WithRegistry(reg))
+	ctx := sequin.WithRuntime(context.Background(), s)
+	lookup := func(id string) *Request {
+		req, err := s.Lookup(ctx, id)
+		require.NoError(t, err)
+		require.NotNil(t, req)
+		return req
+	}
+	workflowID := func(policy sequin.ParentClosePolicy) string {
+		ep := reg.GetEndpoint("local.test.workflow")
+		args := []reflect.Value{{}, reflect.ValueOf(policy)}
+		ep.SetContext(ctx, args)
+		id, err := s.RequestID(ep, args)
+		require.NoError(t, err)
+		return id
+	}
+
+	// Abandoned calls continue after the workflow completes.
+	id, err := workflow(ctx, sequin.ParentCloseAbandon)
+	require.NoError(t, err)
+	// The call is stored once it starts executing.
+	var req *Request
+	require.Eventually(t, func() bool {
+		req, err = s.Lookup(ctx, id)
+		return err == nil && req != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, StatusRunning, req.Status())
+	require.Empty(t, req.ParentID)
+	require.Equal(t, workflowID(sequin.ParentCloseAbandon), req.Originator)
+	require.Equal(t, []Child{{ID: id, Name: "local.test.child", Detached: true}},
+		lookup(req.Originator).Children)
+	close(release["abandon"])
+	// The request has a top-level ID, so a top-level call shares its results.
+	out, err := child(ctx, "abandon")
+	require.NoError(t, err)
+	require.Equal(t, "abandon", out)
+	require.Equal(t, StatusSucceeded, lookup(id).Status())
+
+	// Cancelled calls are cancelled once the workflow completes.
+	id, err = workflow(ctx, sequin.ParentCloseCancel)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return lookup(id).Status() == StatusFailed
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The workflow waits for calls made with ParentCloseWait.
+	done := make(chan string)
+	go func() {
+		id, err := workflow(ctx, sequin.ParentCloseWait)
+		require.NoError(t, err)
+		done <- id
+	}()
+	select {
+	case <-done:
+		t.Fatal("workflow completed before the detached call")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release["wait"])
+	id = <-done
+	require.Equal(t, StatusSucceeded, lookup(id).Status())
+}
+
+func TestServer_DetachDraining(t *testing.T) {
+	reg := registry.New()
+	child := sequin.RegisterIn(reg, func(_ context.Context, name string) (string, error) {
+		return name, nil
+	}, sequin.Name("local.test.child"))
+	entered, proceed := make(chan struct{}), make(chan struct{})
+	workflow := sequin.RegisterIn(reg, func(ctx context.Context) (string, error) {
+		close(entered)
+		<-proceed
+		return child(sequin.Detach(ctx, sequin.ParentCloseAbandon), "late")
+	}, sequin.Name("local.test.workflow"))
+
+	s := NewServer(WithRegistry(reg))
+	ctx := sequin.WithRuntime(context.Background(), s)
+	errc := make(chan error, 1)
+	go func() {
+		_, err := workflow(ctx)
+		errc <- err
+	}()
+	<-entered
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- s.Shutdown(context.Background())
+	}()
+	require.Eventually(t, func() bool {
+		return s.Health() == Draining
+	}, 5*time.Second, time.Millisecond)
+
+	// Detached calls are refused once draining, and the failure isn't stored.
+	close(proceed)
+	require.ErrorIs(t, <-errc, ErrShutdown)
+	require.NoError(t, <-shutdown)
+	reqs, _, err := s.List(ctx, Query{Name: "local.test.workflow"})
+	require.NoError(t, err)
+	for _, req := range reqs {
+		require.False(t, req.Done)
+	}
+}
+
+func TestServer_Compensate(t *testing.T) {
+	reg := registry.New()
+	var mu sync.Mutex
+	var undone []string
+	executed := map[string]int{}
+	record := func(log *[]string, entry string) {
+		mu.Lock()
+		defer mu.Unlock()
+		executed[entry]++
+		if log != nil {
+			*log = append(*log, entry)
+		}
+	}
+	block := true
+	blocked := make(chan struct{})
+
+	reserve := sequin.RegisterIn(reg, func(ctx context.Context, id string) (string, error) {
+		record(nil, "reserve "+id)
+		return "reservation-" + id, nil
+	}, sequin.Name("local.test.reserve"), sequin.Compensate("local.test.release"))
+	sequin.RegisterIn(reg, func(ctx context.Context, id string) error {
+		record(&undone, "release "+id)
+		if id == "b" && block {
+			close(blocked)
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}, sequin.Name("local.test.release"))
+	charge := sequin.RegisterIn(reg, func(ctx context.Context, id string) error {
+		record(nil, "charge "+id)
+		return nil
+	}, sequin.Name("local.test.charge"))
+	sequin.RegisterIn(reg, func(ctx context.Context, id string) error {
+		record(&undone, "refund "+id)
+		return errors.New("refund failed")
+	}, sequin.Name("local.test.refund"))
+	ship := sequin.RegisterIn(reg, func(ctx context.Context, id string) error {
+		record(nil, "ship "+id)
+		return errors.New("out of stock")
+	}, sequin.Name("local.test.ship"))
+	workflow := sequin.RegisterIn(reg, func(ctx context.Context) error {
+		if _, err := reserve(ctx, "a"); err != nil {
+			return err
+		}
+		if _, err := reserve(ctx, "b"); err != nil {
+			return err
+		}
+		if err := charge(sequin.WithCompensation(ctx, "local.test.refund"), "c"); err != nil {
+			return err
+		}
+		return ship(ctx, "d")
+	}, sequin.Name("local.test.workflow"))
+
+	store := NewMemoryStore()
+	s := NewServer(WithRegistry(reg), WithStore(store))
+	ctx := sequin.WithRuntime(context.Background(), s)
+	done := make(chan error)
+	go func() {
+		done <- workflow(ctx)
+	}()
+
+	// Interrupt the workflow while it is compensating.
+	<-blocked
+	expired, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, s.Shutdown(expired), context.Canceled)
+	require.ErrorIs(t, <-done, ErrShutdown)
+
+	// Compensations which completed are not repeated when resumed.
+	block = false
+	s = NewServer(WithRegistry(reg), WithStore(store))
+	ctx = sequin.WithRuntime(context.Background(), s)
+	require.EqualError(t, workflow(ctx), "out of stock")
+	require.Equal(t, []string{"refund c", "release b", "release b", "release a"}, undone)
+	for _, step := range []string{"reserve a", "reserve b", "charge c", "ship d", "refund c", "release a"} {
+		require.Equal(t, 1, executed[step], step)
+	}
+
+	ep := reg.GetEndpoint("local.test.workflow")
+	args := []reflect.Value{{}}
+	ep.SetContext(ctx, args)
+	id, err := s.RequestID(ep, args)
+	require.NoError(t, err)
+	req, err := s.Lookup(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, req.Status())
+	require.Len(t, req.Compensations, 3)
+	var names, errs []string
+	for i, c := range req.Compensations {
+		require.Equal(t, req.Children[i].ID, c.StepID)
+		require.True(t, c.Done)
+		comp, err := s.Lookup(ctx, c.RequestID)
+		require.NoError(t, err)
+		require.Equal(t, c.Name, comp.Name)
+		require.Equal(t, id, comp.ParentID)
+		names = append(names, c.Name)
+		errs = append(errs, c.Error)
+	}
+	require.Equal(t, []string{"local.test.release", "local.test.release", "local.test.refund"}, names)
+	require.Equal(t, []string{"", "", "refund failed"}, errs)
+
+	// Compensating endpoints must exist, and take the arguments of the step.
+	invalid := sequin.RegisterIn(reg, func(ctx context.Context, compensation string) error {
+		return charge(sequin.WithCompensation(ctx, compensation), "e")
+	}, sequin.Name("local.test.invalid"))
+	require.ErrorContains(t, invalid(ctx, "local.test.missing"),
+		"unknown compensating endpoint local.test.missing for local.test.charge")
+	require.ErrorContains(t, invalid(ctx, "local.test.workflow"),
+		"compensating endpoint local.test.workflow does not take the arguments of local.test.charge")
+}
+
+func TestServer_CompensateLate(t *testing.T) {
+	reg := registry.New()
+	reserve := sequin.RegisterIn(reg, func(_ context.Context, id string) (string, error) {
+		return "reservation-" + id, nil
+	}, sequin.Name("local.test.reserve"), sequin.Compensate("local.test.release"))
+	sequin.RegisterIn(reg, func(context.Context, string) error {
+		return nil
+	}, sequin.Name("local.test.release"))
+	proceed := make(chan struct{})
+	late := make(chan error, 1)
+	workflow := sequin.RegisterIn(reg, func(ctx context.Context) error {
+		// A call made after the execution returns isn't awaited by it.
+		ctx = context.WithoutCancel(ctx)
+		go func() {
+			<-proceed
+			_, err := reserve(ctx, "late")
+			late <- err
+		}()
+		return nil
+	}, sequin.Name("local.test.workflow"))
+
+	s := NewServer(WithRegistry(reg))
+	ctx := sequin.WithRuntime(context.Background(), s)
+	require.NoError(t, workflow(ctx))
+	close(proceed)
+	require.ErrorIs(t, <-late, ErrLateCompensation)
+
+	reqs, _, err := s.List(ctx, Query{Name: "local.test.workflow"})
+	require.NoError(t, err)
+	require.Len(t, reqs, 1)
+	require.True(t, reqs[0].Done)
+	require.Empty(t, reqs[0].Compensations)
+}
@@ -0,0 +1,119 @@
+package local
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Request is the stored state of a request.
+type Request struct {
+	ID      string
+	Name    string // Endpoint name.
+	Version int    // Endpoint version which started the request.
+
+	ParentID   string            // Request whose execution issued the request, if any.
+	Originator string            // Request whose execution issued the request, if detached.
+	Labels     map[string]string // Labels of the call which created the request.
+	Attempts   int               // Number of times execution of the request has started.
+
+	SubmittedAt time.Time // When the request was created.
+	StartedAt   time.Time // When the latest execution of the request started.
+	FinishedAt  time.Time // When the request completed.
+
+	Done    bool     // Set once the request has completed.
+	Failed  bool     // Set if the request completed with an error.
+	Results [][]byte // Encoded results, set once Done.
+
+	Children []Child        // Child requests, in the order they were issued.
+	Versions map[string]int // Versions recorded by GetVersion, by change ID.
+
+	// Compensations of child requests which completed, in the order they
+	// completed. See sequin.Compensate.
+	Compensations []Compensation
+}
+
+// Child identifies a child request issued during execution of a request.
+type Child struct {
+	ID       string
+	Name     string
+	Detached bool // Set if the child was issued by a detached call.
+}
+
+// Store persists request state, allowing a request to be resumed by a
+// different Server than the one which started it. Stores must be safe for
+// concurrent use.
+type Store interface {
+	// Get returns the request with the given ID, or nil if it is not found.
+	Get(ctx context.Context, id string) (*Request, error)
+	// Put creates or replaces a request.
+	Put(ctx context.Context, req *Request) error
+}
+
+// Deleter is implemented by stores which can delete requests.
+type Deleter interface {
+	// Delete removes the request with the given ID, if it exists.
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore is a Store which keeps requests in memory.
+type MemoryStore struct {
+	mu       sync.Mutex
+	requests map[string]*Request
+}
+
+var _ Store = &MemoryStore{}
+var _ Sizer = &MemoryStore{}
+var _ Lister = &MemoryStore{}
+var _ Deleter = &MemoryStore{}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		requests: make(map[string]*Request),
+	}
+}
+
+func (ms *MemoryStore) Get(_ context.Context, id string) (*Request, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	req, ok := ms.requests[id]
+	if !ok {
+		return nil, nil
+	}
+	return req.clone(), nil
+}
+
+func (ms *MemoryStore) Put(_ context.Context, req *Request) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.requests[req.ID] = req.clone()
+	return nil
+}
+
+// Delete implements Deleter.
+func (ms *MemoryStore) Delete(_ context.Context, id string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.requests, id)
+	return nil
+}
+
+// Len returns the number of stored requests.
+func (ms *MemoryStore) Len() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return len(ms.requests)
+}
+
+// clone returns a copy of the request which shares no mutable state.
+func (r *Request) clone() *Request {
+	out := *r
+	out.Results = slices.Clone(r.Results)
+	out.Children = slices.Clone(r.Children)
+	out.Versions = maps.Clone(r.Versions)
+	out.Labels = maps.Clone(r.Labels)
+	out.Compensations = slices.Clone(r.Compensations)
+	return &out
+}
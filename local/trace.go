@@ -0,0 +1,30 @@
+package local
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer used to trace calls of endpoints.
+const TracerName = "github.com/vgough/sequin/local"
+
+// Attributes recorded on the span of each call of an endpoint.
+const (
+	AttrEndpoint        = attribute.Key("sequin.endpoint")
+	AttrRequestID       = attribute.Key("sequin.request_id")
+	AttrParentRequestID = attribute.Key("sequin.parent_request_id")
+	AttrCacheHit        = attribute.Key("sequin.cache_hit") // results were already stored.
+	AttrShared          = attribute.Key("sequin.shared")    // execution was shared with concurrent calls.
+	AttrAttempt         = attribute.Key("sequin.attempt")   // times the request has been executed.
+)
+
+// WithTracerProvider sets the provider of the tracer used to trace calls of
+// endpoints. The default is the global provider.
+//
+// Each call produces a span, which is the parent of the spans of calls made
+// during its execution.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(s *Server) {
+		s.tracer = tp.Tracer(TracerName)
+	}
+}
@@ -0,0 +1,178 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/vgough/sequin"
+	"github.com/vgough/sequin/registry"
+)
+
+func spanAttrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestServer_Tracing(t *testing.T) {
+	reg := registry.New()
+	square := sequin.RegisterIn(reg, func(_ context.Context, n int) (int, error) {
+		if n < 0 {
+			return 0, errors.New("negative input")
+		}
+		return n * n, nil
+	}, sequin.Name("local.test.square"))
+	sumSquares := sequin.RegisterIn(reg, func(ctx context.Context, ns []int) (int, error) {
+		var total int
+		for _, n := range ns {
+			sq, err := square(ctx, n)
+			if err != nil {
+				return 0, err
+			}
+			total += sq
+		}
+		return total, nil
+	}, sequin.Name("local.test.sum-squares"))
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	s := NewServer(WithRegistry(reg), WithTracerProvider(tp))
+
+	ctx, root := tp.Tracer("test").Start(context.Background(), "root")
+	ctx = sequin.WithRuntime(ctx, s)
+	total, err := sumSquares(ctx, []int{1, 2})
+	require.NoError(t, err)
+	require.Equal(t, 5, total)
+	root.End()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 4)
+	children, parent := spans[:2], spans[2]
+	require.Equal(t, "local.test.sum-squares", parent.Name())
+	require.Equal(t, root.SpanContext().SpanID(), parent.Parent().SpanID())
+	attrs := spanAttrs(parent)
+	require.Equal(t, "local.test.sum-squares", attrs[AttrEndpoint].AsString())
+	require.False(t, attrs[AttrCacheHit].AsBool())
+	require.False(t, attrs[AttrShared].AsBool())
+	require.EqualValues(t, 1, attrs[AttrAttempt].AsInt64())
+	parentID := attrs[AttrRequestID].AsString()
+	require.NotEmpty(t, parentID)
+	_, ok := attrs[AttrParentRequestID]
+	require.False(t, ok)
+
+	// Calls made during execution are children of the call.
+	for _, child := range children {
+		require.Equal(t, "local.test.square", child.Name())
+		require.Equal(t, parent.SpanContext().TraceID(), child.SpanContext().TraceID())
+		require.Equal(t, parent.SpanContext().SpanID(), child.Parent().SpanID())
+		attrs := spanAttrs(child)
+		require.Equal(t, parentID, attrs[AttrParentRequestID].AsString())
+		require.NotEqual(t, parentID, attrs[AttrRequestID].AsString())
+	}
+
+	// Repeated calls use the stored results.
+	_, err = sumSquares(ctx, []int{1, 2})
+	require.NoError(t, err)
+	spans = rec.Ended()
+	require.Len(t, spans, 5)
+	attrs = spanAttrs(spans[4])
+	require.True(t, attrs[AttrCacheHit].AsBool())
+	require.Equal(t, parentID, attrs[AttrRequestID].AsString())
+	_, ok = attrs[AttrAttempt]
+	require.False(t, ok)
+
+	// Failures are recorded in the span status.
+	_, err = square(ctx, -1)
+	require.Error(t, err)
+	spans = rec.Ended()
+	require.Equal(t, codes.Error, spans[len(spans)-1].Status().Code)
+	require.Equal(t, "negative input", spans[len(spans)-1].Status().Description)
+}
+
+func TestServer_TracingAttempts(t *testing.T) {
+	reg := registry.New()
+	double := sequin.RegisterIn(reg, func(_ context.Context, n int) (int, error) {
+		return n * 2, nil
+	}, sequin.Name("local.test.double"))
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	s := NewServer(WithRegistry(reg), WithTracerProvider(tp))
+	ctx := sequin.WithRuntime(context.Background(), s)
+
+	// A previous execution of the request was interrupted.
+	ep := reg.GetEndpoint("local.test.double")
+	args := []reflect.Value{{}, reflect.ValueOf(4)}
+	ep.SetContext(ctx, args)
+	id, err := s.RequestID(ep, args)
+	require.NoError(t, err)
+	require.NoError(t, s.store.Put(ctx, &Request{ID: id, Name: ep.Name, Version: ep.Version, Attempts: 1}))
+
+	v, err := double(ctx, 4)
+	require.NoError(t, err)
+	require.Equal(t, 8, v)
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+	require.EqualValues(t, 2, spanAttrs(spans[0])[AttrAttempt].AsInt64())
+
+	req, err := s.Lookup(ctx, id)
+	require.NoError(t, err)
+	require.True(t, req.Done)
+	require.Equal(t, 2, req.Attempts)
+}
+
+func TestServer_TracingShared(t *testing.T) {
+	reg := registry.New()
+	release := make(chan struct{})
+	slow := sequin.RegisterIn(reg, func(_ context.Context, n int) (int, error) {
+		<-release
+		return n, nil
+	}, sequin.Name("local.test.slow"))
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	s := NewServer(WithRegistry(reg), WithTracerProvider(tp))
+	ctx := sequin.WithRuntime(context.Background(), s)
+
+	errc := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, err := slow(ctx, 1)
+			errc <- err
+		}()
+	}
+	// Wait for both calls to be made before completing the execution.
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, n := range s.calls {
+			return n == 2
+		}
+		return false
+	}, 5*time.Second, time.Millisecond)
+	close(release)
+	require.NoError(t, <-errc)
+	require.NoError(t, <-errc)
+
+	// Both calls describe the shared execution.
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		attrs := spanAttrs(span)
+		require.True(t, attrs[AttrShared].AsBool())
+		require.Equal(t, attribute.BOOL, attrs[AttrCacheHit].Type())
+		require.False(t, attrs[AttrCacheHit].AsBool())
+		require.EqualValues(t, 1, attrs[AttrAttempt].AsInt64())
+	}
+}
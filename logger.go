@@ -0,0 +1,28 @@
+package sequin
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/vgough/sequin/internal"
+)
+
+var loggerMD internal.MDKey[*slog.Logger]
+
+// WithLogger stores the logger in the context.
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return loggerMD.Set(ctx, l)
+}
+
+// Logger returns the logger stored in the context, or slog.Default if there
+// is none.
+//
+// Runtimes store a logger in the context of each execution, annotated with
+// the endpoint name, request ID and parent request ID, so that logs of steps
+// can be correlated with the calls which issued them.
+func Logger(ctx context.Context) *slog.Logger {
+	if l := loggerMD.Get(ctx); l != nil {
+		return l
+	}
+	return slog.Default()
+}
@@ -0,0 +1,232 @@
+// Package metrics exposes measurements of a local.Server in the Prometheus
+// text exposition format.
+//
+//	m := metrics.New()
+//	srv := local.NewServer(local.WithMetrics(m))
+//	http.Handle("/metrics", m)
+//
+// The following metrics are exposed, labelled by endpoint unless noted:
+//
+//	sequin_executions_total              executions of requests
+//	sequin_execution_errors_total        executions which returned an error
+//	sequin_executions_in_flight          executions in progress
+//	sequin_execution_duration_seconds    histogram of execution latency
+//	sequin_cache_hits_total              calls answered with stored results
+//	sequin_shared_waits_total            calls which waited for a concurrent execution
+//	sequin_store_requests                requests held by the store (unlabelled)
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vgough/sequin/local"
+)
+
+// ContentType is the content type of the exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the default upper bounds, in seconds, of the execution
+// latency histogram.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// Prometheus records the measurements of a server, and serves them over HTTP
+// in the Prometheus text exposition format.
+type Prometheus struct {
+	buckets []float64
+
+	mu          sync.Mutex
+	executions  map[string]float64
+	errors      map[string]float64
+	inFlight    map[string]float64
+	durations   map[string]*histogram
+	cacheHits   map[string]float64
+	sharedWaits map[string]float64
+	storeSize   float64
+}
+
+var _ local.Metrics = &Prometheus{}
+var _ http.Handler = &Prometheus{}
+
+// Option configures a Prometheus.
+type Option func(*Prometheus)
+
+// WithBuckets sets the upper bounds, in seconds, of the execution latency
+// histogram. The default is DefaultBuckets.
+func WithBuckets(buckets ...float64) Option {
+	return func(p *Prometheus) {
+		p.buckets = slices.Sorted(slices.Values(buckets))
+	}
+}
+
+// New returns a Prometheus with no recorded measurements.
+func New(opts ...Option) *Prometheus {
+	p := &Prometheus{
+		buckets:     DefaultBuckets,
+		executions:  make(map[string]float64),
+		errors:      make(map[string]float64),
+		inFlight:    make(map[string]float64),
+		durations:   make(map[string]*histogram),
+		cacheHits:   make(map[string]float64),
+		sharedWaits: make(map[string]float64),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// ExecStarted implements local.Metrics.
+func (p *Prometheus) ExecStarted(endpoint string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.executions[endpoint]++
+	p.inFlight[endpoint]++
+}
+
+// ExecFinished implements local.Metrics.
+func (p *Prometheus) ExecFinished(endpoint string, elapsed time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inFlight[endpoint]--
+	if err != nil {
+		p.errors[endpoint]++
+	}
+	h := p.durations[endpoint]
+	if h == nil {
+		h = &histogram{counts: make([]float64, len(p.buckets))}
+		p.durations[endpoint] = h
+	}
+	h.observe(p.buckets, elapsed.Seconds())
+}
+
+// CacheHit implements local.Metrics.
+func (p *Prometheus) CacheHit(endpoint string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cacheHits[endpoint]++
+}
+
+// SharedWait implements local.Metrics.
+func (p *Prometheus) SharedWait(endpoint string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sharedWaits[endpoint]++
+}
+
+// StoreSize implements local.Metrics.
+func (p *Prometheus) StoreSize(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.storeSize = float64(n)
+}
+
+// ServeHTTP writes the recorded measurements.
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_, _ = p.WriteTo(w)
+}
+
+// WriteTo writes the recorded measurements in the text exposition format.
+// Series are ordered by endpoint, so the output is deterministic.
+func (p *Prometheus) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	writeCounters(cw, "sequin_executions_total", "Executions of requests.", "counter", p.executions)
+	writeCounters(cw, "sequin_execution_errors_total", "Executions which returned an error.", "counter", p.errors)
+	writeCounters(cw, "sequin_executions_in_flight", "Executions in progress.", "gauge", p.inFlight)
+
+	const name = "sequin_execution_duration_seconds"
+	writeHeader(cw, name, "Latency of executions.", "histogram")
+	for _, ep := range slices.Sorted(maps.Keys(p.durations)) {
+		h := p.durations[ep]
+		label := endpointLabel(ep)
+		var cumulative float64
+		for i, le := range p.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(cw, "%s_bucket{%s,le=%q} %s\n", name, label, formatFloat(le), formatFloat(cumulative))
+		}
+		fmt.Fprintf(cw, "%s_bucket{%s,le=\"+Inf\"} %s\n", name, label, formatFloat(h.count))
+		fmt.Fprintf(cw, "%s_sum{%s} %s\n", name, label, formatFloat(h.sum))
+		fmt.Fprintf(cw, "%s_count{%s} %s\n", name, label, formatFloat(h.count))
+	}
+
+	writeCounters(cw, "sequin_cache_hits_total", "Calls answered with stored results.", "counter", p.cacheHits)
+	writeCounters(cw, "sequin_shared_waits_total", "Calls which waited for a concurrent execution.", "counter", p.sharedWaits)
+	writeHeader(cw, "sequin_store_requests", "Requests held by the store.", "gauge")
+	fmt.Fprintf(cw, "sequin_store_requests %s\n", formatFloat(p.storeSize))
+
+	if cw.err == nil {
+		cw.err = cw.w.(*bufio.Writer).Flush()
+	}
+	return cw.n, cw.err
+}
+
+type histogram struct {
+	counts []float64 // non-cumulative count per bucket.
+	count  float64
+	sum    float64
+}
+
+func (h *histogram) observe(buckets []float64, v float64) {
+	if i, _ := slices.BinarySearch(buckets, v); i < len(buckets) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += v
+}
+
+func writeHeader(w io.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writeCounters(w io.Writer, name, help, typ string, values map[string]float64) {
+	writeHeader(w, name, help, typ)
+	for _, ep := range slices.Sorted(maps.Keys(values)) {
+		fmt.Fprintf(w, "%s{%s} %s\n", name, endpointLabel(ep), formatFloat(values[ep]))
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func endpointLabel(endpoint string) string {
+	return `endpoint="` + labelEscaper.Replace(endpoint) + `"`
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// countingWriter counts bytes written, and retains the first error.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
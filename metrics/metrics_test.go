@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/vgough/sequin"
+	"github.com/vgough/sequin/local"
+	"github.com/vgough/sequin/registry"
+)
+
+func TestPrometheus(t *testing.T) {
+	reg := registry.New()
+	release := make(chan struct{})
+	square := sequin.RegisterIn(reg, func(_ context.Context, n int) (int, error) {
+		if n < 0 {
+			return 0, errors.New("negative input")
+		}
+		if n == 0 {
+			<-release
+		}
+		return n * n, nil
+	}, sequin.Name("metrics.test.square"))
+
+	m := New(WithBuckets(10, 0.05))
+	ctx := sequin.WithRuntime(context.Background(), local.NewServer(local.WithRegistry(reg), local.WithMetrics(m)))
+
+	_, err := square(ctx, 2)
+	require.NoError(t, err)
+	_, err = square(ctx, 2)
+	require.NoError(t, err)
+	_, err = square(ctx, -1)
+	require.Error(t, err)
+
+	// Concurrent calls of a request share its execution.
+	done := make(chan struct{})
+	for range 2 {
+		go func() {
+			_, err := square(ctx, 0)
+			require.NoError(t, err)
+			done <- struct{}{}
+		}()
+	}
+	require.Eventually(t, func() bool {
+		return strings.Contains(scrape(t, m), `sequin_executions_in_flight{endpoint="metrics.test.square"} 1`)
+	}, 5*time.Second, 10*time.Millisecond)
+	// Give the second call time to join the execution.
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	<-done
+	<-done
+
+	out := scrape(t, m)
+	for _, line := range []string{
+		"# TYPE sequin_executions_total counter",
+		`sequin_executions_total{endpoint="metrics.test.square"} 3`,
+		`sequin_execution_errors_total{endpoint="metrics.test.square"} 1`,
+		`sequin_executions_in_flight{endpoint="metrics.test.square"} 0`,
+		"# TYPE sequin_execution_duration_seconds histogram",
+		`sequin_execution_duration_seconds_bucket{endpoint="metrics.test.square",le="0.05"} 2`,
+		`sequin_execution_duration_seconds_bucket{endpoint="metrics.test.square",le="10"} 3`,
+		`sequin_execution_duration_seconds_bucket{endpoint="metrics.test.square",le="+Inf"} 3`,
+		`sequin_execution_duration_seconds_count{endpoint="metrics.test.square"} 3`,
+		`sequin_cache_hits_total{endpoint="metrics.test.square"} 1`,
+		`sequin_shared_waits_total{endpoint="metrics.test.square"} 1`,
+		"sequin_store_requests 3",
+	} {
+		require.Contains(t, out, line+"\n")
+	}
+}
+
+func TestPrometheus_Escaping(t *testing.T) {
+	m := New()
+	m.CacheHit("a\"b\\c\nd")
+	require.Contains(t, scrape(t, m), `sequin_cache_hits_total{endpoint="a\"b\\c\nd"} 1`)
+}
+
+func scrape(t *testing.T, m *Prometheus) string {
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+	res, err := srv.Client().Get(srv.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, ContentType, res.Header.Get("Content-Type"))
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return string(data)
+}
@@ -2,6 +2,9 @@
 package sequin
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
 
 	"github.com/vgough/sequin/internal"
@@ -16,7 +19,17 @@ import (
 // must return an error as the last result.
 //
 // The error return requirement is to allow for error reporting by the runtime.
+//
+// The function is added to the default registry. Arguments and results must be
+// encodable, which is checked when calls are made. Use registry.Default.Validate
+// to check all registered functions up front, such as from a test.
 func Register[T any](fn T, opts ...RegisterOpt) T {
+	return RegisterIn(registry.Default, fn, opts...)
+}
+
+// RegisterIn registers a function in a specific registry.
+// See Register for details.
+func RegisterIn[T any](r *registry.Registry, fn T, opts ...RegisterOpt) T {
 	fnV := reflect.ValueOf(fn)
 	ep, err := registry.NewEndpoint(fnV)
 	if err != nil {
@@ -32,7 +45,7 @@ func Register[T any](fn T, opts ...RegisterOpt) T {
 		}
 	}
 
-	if err := registry.Register(ep); err != nil {
+	if err := r.Register(ep); err != nil {
 		panic(err)
 	}
 
@@ -56,6 +69,163 @@ func GlobalID() RegisterOpt {
 	}
 }
 
+// Name sets an explicit name for the endpoint.
+//
+// The default name is derived from the Go symbol name of the function, so
+// changes when the function is renamed or moved. The name identifies stored
+// results and is used by remote callers, so explicit names are recommended
+// for endpoints which need to remain stable. See registry.ValidateName for
+// the allowed format.
+func Name(name string) RegisterOpt {
+	return func(ep *registry.Endpoint) error {
+		if err := registry.ValidateName(name); err != nil {
+			return err
+		}
+		ep.Name = name
+		return nil
+	}
+}
+
+// Alias adds previous names which the endpoint continues to answer to.
+//
+// This allows renaming an endpoint without orphaning its stored results or
+// breaking remote callers which use the previous name. Aliases are not
+// required to be valid explicit names, so that default names can be migrated.
+func Alias(names ...string) RegisterOpt {
+	return func(ep *registry.Endpoint) error {
+		for _, name := range names {
+			if name == "" {
+				return errors.New("endpoint alias cannot be empty")
+			}
+		}
+		ep.Aliases = append(ep.Aliases, names...)
+		return nil
+	}
+}
+
+// ArgNames names the arguments passed by callers, in order.
+//
+// Context and injected arguments are not named. Names allow callers which
+// don't use the Go wrapper, such as the JSON gateway, to pass arguments by
+// name rather than by position.
+func ArgNames(names ...string) RegisterOpt {
+	return func(ep *registry.Endpoint) error {
+		seen := make(map[string]bool, len(names))
+		for _, name := range names {
+			switch {
+			case name == "":
+				return errors.New("argument name cannot be empty")
+			case seen[name]:
+				return fmt.Errorf("duplicate argument name %q", name)
+			}
+			seen[name] = true
+		}
+		ep.Metadata[internal.ArgNames] = names
+		return nil
+	}
+}
+
+// Bind binds a method endpoint to a receiver.
+//
+// The function must be a method expression taking the receiver as the first
+// argument, followed by the context, such as (*Client).Fetch. Calls use the
+// bound receiver, and the receiver passed by the caller is ignored, so may be
+// nil. The receiver is never encoded or included in the request ID.
+func Bind(recv any) RegisterOpt {
+	return func(ep *registry.Endpoint) error {
+		v := reflect.ValueOf(recv)
+		if err := checkReceiver(ep, v.Type()); err != nil {
+			return err
+		}
+		ep.MethodBinding = v
+		return nil
+	}
+}
+
+// BindProvider is like Bind, but resolves the receiver using fn each time the
+// endpoint is executed. The context passed to fn is the execution context.
+func BindProvider[R any](fn func(context.Context) (R, error)) RegisterOpt {
+	return func(ep *registry.Endpoint) error {
+		if err := checkReceiver(ep, reflect.TypeFor[R]()); err != nil {
+			return err
+		}
+		ep.MethodProvider = func(ctx context.Context) (reflect.Value, error) {
+			recv, err := fn(ctx)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(&recv).Elem(), nil
+		}
+		return nil
+	}
+}
+
+// Inject marks arguments of type T as injected.
+//
+// Injected arguments are provided at execution time by the provider for T in
+// the runtime's registry, and are never encoded or included in the request ID.
+// This allows steps to accept values such as database handles, clients and
+// loggers. See Provide.
+//
+// When called without a runtime, the values passed by the caller are used.
+func Inject[T any]() RegisterOpt {
+	return func(ep *registry.Endpoint) error {
+		t := reflect.TypeFor[T]()
+		if t == registry.ContextType || t == registry.ErrorType {
+			return fmt.Errorf("arguments of type %v can't be injected", t)
+		}
+		if ep.InjectedArgs == nil {
+			ep.InjectedArgs = make([]bool, len(ep.InputTypes))
+		}
+		var found bool
+		for i, it := range ep.InputTypes {
+			if it == t {
+				ep.InjectedArgs[i] = true
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("function has no argument of type %v to inject", t)
+		}
+		return nil
+	}
+}
+
+// Provide registers a provider in the default registry for arguments of type
+// T, which are marked as injected using Inject.
+//
+// The provider is called each time an endpoint is executed by a runtime, with
+// the execution context.
+func Provide[T any](fn func(context.Context) (T, error)) {
+	ProvideIn(registry.Default, fn)
+}
+
+// ProvideIn registers a provider in a specific registry.
+// See Provide for details.
+func ProvideIn[T any](r *registry.Registry, fn func(context.Context) (T, error)) {
+	err := r.Provide(reflect.TypeFor[T](), func(ctx context.Context) (reflect.Value, error) {
+		v, err := fn(ctx)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&v).Elem(), nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func checkReceiver(ep *registry.Endpoint, recvT reflect.Type) error {
+	switch {
+	case ep.ContextIndex != 1:
+		return errors.New("binding requires the receiver as the first argument, followed by the context")
+	case recvT == nil || !recvT.AssignableTo(ep.InputTypes[0]):
+		return fmt.Errorf("receiver of type %v can't be bound to argument of type %v",
+			recvT, ep.InputTypes[0])
+	}
+	return nil
+}
+
 func contextDispatch(ep *registry.Endpoint) func([]reflect.Value) []reflect.Value {
 	return func(args []reflect.Value) []reflect.Value {
 		ctx := ep.GetContext(args)
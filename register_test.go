@@ -3,9 +3,12 @@ package sequin
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"reflect"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -84,3 +87,211 @@ func (st *StatefulType) SetState(_ context.Context, state int) error {
 }
 
 var SetState = Register((*StatefulType).SetState)
+
+func TestGetVersion(t *testing.T) {
+	ctx := context.Background()
+	v, err := GetVersion(ctx, "change", DefaultVersion, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, v)
+
+	_, err = GetVersion(ctx, "change", 2, 1)
+	require.Error(t, err)
+
+	ep := registry.GetEndpoint("github.com/vgough/sequin.versioned")
+	require.NotNil(t, ep)
+	require.Equal(t, 3, ep.Version)
+}
+
+var Versioned = Register(versioned, Version(3))
+
+func versioned(_ context.Context) error {
+	return nil
+}
+
+func TestRegisterName(t *testing.T) {
+	ep := registry.GetEndpoint("sequin.test.stable")
+	require.NotNil(t, ep)
+	require.Equal(t, "sequin.test.stable", ep.Name)
+	require.Same(t, ep, registry.GetEndpoint("github.com/vgough/sequin.renamed"))
+	require.Same(t, ep, registry.GetEndpoint("sequin.test.old-name"))
+
+	endpoints := registry.RegisteredEndpoints()
+	require.Contains(t, endpoints, "sequin.test.stable")
+	require.NotContains(t, endpoints, "sequin.test.old-name")
+
+	require.Panics(t, func() {
+		Register(renamed, Name("sequin..invalid"))
+	})
+	require.Panics(t, func() {
+		Register(renamed, Name("sequin.test.conflict"), Alias("sequin.test.old-name"))
+	})
+}
+
+var Renamed = Register(renamed, Name("sequin.test.stable"),
+	Alias("github.com/vgough/sequin.renamed", "sequin.test.old-name"))
+
+func renamed(_ context.Context) error {
+	return nil
+}
+
+func TestBind(t *testing.T) {
+	st := &StatefulType{}
+	setState := RegisterIn(registry.New(), (*StatefulType).SetState, Name("sequin.test.set-state"), Bind(st))
+	require.NoError(t, setState(nil, context.Background(), 3))
+	require.Equal(t, 3, st.state)
+
+	require.Panics(t, func() {
+		Register((*StatefulType).SetState, Name("sequin.test.bad-bind"), Bind(42))
+	})
+	require.Panics(t, func() {
+		Register(isEven, Name("sequin.test.not-method"), Bind(st))
+	})
+}
+
+func TestInject(t *testing.T) {
+	require.Panics(t, func() {
+		Register(isEven, Name("sequin.test.no-arg"), Inject[*StatefulType]())
+	})
+	require.Panics(t, func() {
+		Register(isEven, Name("sequin.test.inject-context"), Inject[context.Context]())
+	})
+
+	ep := registry.GetEndpoint("sequin.test.injected")
+	require.NotNil(t, ep)
+	require.Equal(t, []bool{false, true, false}, ep.InjectedArgs)
+	require.True(t, ep.IsInjected(1))
+	require.False(t, ep.IsInjected(2))
+}
+
+var Injected = Register(func(_ context.Context, st *StatefulType, n int) error {
+	return st.SetState(context.Background(), n)
+}, Name("sequin.test.injected"), Inject[*StatefulType]())
+
+func TestArgNames(t *testing.T) {
+	reg := registry.New()
+	RegisterIn(reg, func(_ context.Context, st *StatefulType, n int, s string) error {
+		return nil
+	}, Name("sequin.test.named"), Inject[*StatefulType](), ArgNames("count", "label"))
+	ep := reg.GetEndpoint("sequin.test.named")
+	require.Equal(t, 2, ep.NumArgs())
+	require.Equal(t, []string{"count", "label"}, ep.ArgNames())
+
+	// Only arguments passed by callers are named.
+	require.Panics(t, func() {
+		RegisterIn(reg, isEven, Name("sequin.test.too-many"), ArgNames("n", "extra"))
+	})
+	require.Panics(t, func() {
+		RegisterIn(reg, func(context.Context, int, int) error { return nil },
+			Name("sequin.test.duplicate"), ArgNames("n", "n"))
+	})
+}
+
+func TestLabels(t *testing.T) {
+	ctx := context.Background()
+	require.Nil(t, Labels(ctx))
+	require.Equal(t, ctx, WithLabels(ctx, nil))
+
+	ctx = WithLabels(ctx, map[string]string{"team": "infra", "env": "dev"})
+	child := WithLabels(ctx, map[string]string{"env": "prod"})
+	require.Equal(t, map[string]string{"team": "infra", "env": "dev"}, Labels(ctx))
+	require.Equal(t, map[string]string{"team": "infra", "env": "prod"}, Labels(child))
+
+	// Returned labels are a copy.
+	Labels(child)["env"] = "test"
+	require.Equal(t, "prod", Labels(child)["env"])
+}
+
+func TestMap(t *testing.T) {
+	var active, peak atomic.Int32
+	square := func(_ context.Context, n int) (int, error) {
+		cur := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if cur <= p || peak.CompareAndSwap(p, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Duration(10-n) * time.Millisecond)
+		if n%4 == 3 {
+			return 0, fmt.Errorf("bad input %d", n)
+		}
+		return n * n, nil
+	}
+
+	out, err := Map(context.Background(), []int{0, 1, 2, 4, 5}, square, Parallelism(2))
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 4, 16, 25}, out)
+	require.EqualValues(t, 2, peak.Load())
+
+	out, err = Map(context.Background(), []int{1, 3, 5, 7}, square)
+	var fanOutErr *FanOutError
+	require.ErrorAs(t, err, &fanOutErr)
+	require.Len(t, fanOutErr.Errs, 4)
+	require.Nil(t, fanOutErr.Errs[0])
+	require.EqualError(t, fanOutErr.Errs[1], "bad input 3")
+	require.EqualError(t, err, "2 of 4 calls failed: call 1: bad input 3; call 3: bad input 7")
+	require.Equal(t, []int{1, 0, 25, 0}, out)
+
+	errBoom := errors.New("boom")
+	err = All(context.Background(), []func(context.Context) error{
+		func(context.Context) error { return nil },
+		func(context.Context) error { return errBoom },
+	})
+	require.ErrorIs(t, err, errBoom)
+}
+
+func TestAsync(t *testing.T) {
+	release := make(chan struct{})
+	f := Async(context.Background(), func(ctx context.Context) (int, error) {
+		<-release
+		return 42, nil
+	})
+	require.Empty(t, f.RequestID())
+	select {
+	case <-f.Done():
+		t.Fatal("future completed early")
+	default:
+	}
+	expired, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := f.Wait(expired)
+	require.ErrorIs(t, err, context.Canceled)
+	close(release)
+	v, err := f.Wait(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 42, v)
+
+	f = Async(context.Background(), func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	f.Cancel()
+	<-f.Done()
+	_, err = f.Wait(context.Background())
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestDetach(t *testing.T) {
+	_, ok := DetachPolicy(context.Background())
+	require.False(t, ok)
+	policy, ok := DetachPolicy(Detach(context.Background(), ParentCloseAbandon))
+	require.True(t, ok)
+	require.Equal(t, ParentCloseAbandon, policy)
+	require.Equal(t, "wait", ParentCloseWait.String())
+}
+
+func TestCompensate(t *testing.T) {
+	r := registry.New()
+	fn := func(ctx context.Context, id string) error { return nil }
+	RegisterIn(r, fn, Name("test.reserve"), Compensate("test.release"))
+	ep := r.GetEndpoint("test.reserve")
+
+	ctx := context.Background()
+	require.Equal(t, "test.release", Compensation(ctx, ep))
+	require.Equal(t, "test.cancel", Compensation(WithCompensation(ctx, "test.cancel"), ep))
+	require.Empty(t, Compensation(WithCompensation(ctx, ""), ep))
+	require.Panics(t, func() {
+		RegisterIn(r, fn, Name("test.other"), Compensate(""))
+	})
+}
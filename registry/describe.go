@@ -0,0 +1,114 @@
+package registry
+
+import (
+	"maps"
+	"reflect"
+	"strings"
+
+	"github.com/vgough/sequin/internal"
+)
+
+// EndpointInfo describes a registered endpoint.
+type EndpointInfo struct {
+	Name    string   `json:"name"`
+	Version int      `json:"version"`
+	Aliases []string `json:"aliases,omitempty"`
+
+	Args    []ValueInfo `json:"args"`
+	Results []ValueInfo `json:"results"`
+
+	// ArgsSchema is a JSON Schema for the arguments passed by callers, as an
+	// array in positional order. Context and injected arguments are omitted.
+	ArgsSchema Schema `json:"argsSchema"`
+	// ResultsSchema is a JSON Schema for the results, as an array in
+	// positional order. The error result is omitted.
+	ResultsSchema Schema `json:"resultsSchema"`
+
+	Options  Options        `json:"options"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// ValueInfo describes an argument or result of an endpoint.
+type ValueInfo struct {
+	Index int    `json:"index"`
+	Type  string `json:"type"`           // Go type.
+	Name  string `json:"name,omitempty"` // Argument name, if set.
+
+	// Context is set for context arguments.
+	Context bool `json:"context,omitempty"`
+	// Error is set for the error result.
+	Error bool `json:"error,omitempty"`
+	// Injected is set for arguments provided at execution time.
+	Injected bool `json:"injected,omitempty"`
+}
+
+// Options describes the registration options of an endpoint.
+type Options struct {
+	// GlobalID is set if request IDs are not scoped to the enclosing
+	// execution.
+	GlobalID bool `json:"globalID,omitempty"`
+	// Bound is set if the endpoint is a method bound to a receiver.
+	Bound bool `json:"bound,omitempty"`
+}
+
+// Describe returns a description of the endpoint.
+func Describe(ep *Endpoint) EndpointInfo {
+	info := EndpointInfo{
+		Name:     ep.Name,
+		Version:  ep.Version,
+		Aliases:  ep.Aliases,
+		Metadata: maps.Clone(ep.Metadata),
+	}
+
+	var argTypes []reflect.Type
+	names := ep.ArgNames()
+	for i, t := range ep.InputTypes {
+		vi := ValueInfo{
+			Index:    i,
+			Type:     t.String(),
+			Context:  t == ContextType,
+			Injected: ep.IsInjected(i),
+		}
+		if !vi.Context && !vi.Injected {
+			if n := len(argTypes); n < len(names) {
+				vi.Name = names[n]
+			}
+			argTypes = append(argTypes, t)
+		}
+		info.Args = append(info.Args, vi)
+	}
+
+	var resultTypes []reflect.Type
+	for i, t := range ep.OutputTypes {
+		vi := ValueInfo{Index: i, Type: t.String(), Error: t == ErrorType}
+		if !vi.Error {
+			resultTypes = append(resultTypes, t)
+		}
+		info.Results = append(info.Results, vi)
+	}
+	info.ArgsSchema = tupleSchema(argTypes)
+	info.ResultsSchema = tupleSchema(resultTypes)
+
+	info.Options.GlobalID, _ = ep.Metadata[internal.GlobalIDGen].(bool)
+	info.Options.Bound = ep.IsBound()
+	// Options are reported separately from other metadata.
+	maps.DeleteFunc(info.Metadata, func(k string, _ any) bool {
+		return strings.HasPrefix(k, internal.KeyPrefix)
+	})
+	if len(info.Metadata) == 0 {
+		info.Metadata = nil
+	}
+	return info
+}
+
+// Describe returns descriptions of all endpoints in the registry, sorted by
+// name.
+func (r *Registry) Describe() []EndpointInfo {
+	var infos []EndpointInfo
+	for _, name := range r.RegisteredEndpoints() {
+		if ep := r.GetEndpoint(name); ep != nil {
+			infos = append(infos, Describe(ep))
+		}
+	}
+	return infos
+}
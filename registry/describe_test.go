@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	sequinv1 "github.com/vgough/sequin/gen/sequin/v1"
+	"github.com/vgough/sequin/internal"
+)
+
+type order struct {
+	ID       string `json:"id"`
+	Quantity uint   `json:"qty,omitempty"`
+	Placed   time.Time
+	Parent   *order `json:"parent"`
+	Notes    []byte
+	internal int
+	Skipped  string `json:"-"`
+}
+
+func TestDescribe(t *testing.T) {
+	ep, err := NewEndpoint(reflect.ValueOf(func(context.Context, *order, map[string]float64) ([]order, error) {
+		return nil, nil
+	}))
+	require.NoError(t, err)
+	ep.Name = "registry.test.orders"
+	ep.Version = 2
+	ep.Metadata[internal.GlobalIDGen] = true
+	ep.Metadata["team"] = "fulfillment"
+
+	r := New()
+	require.NoError(t, r.Register(ep))
+	infos := r.Describe()
+	require.Len(t, infos, 1)
+	info := infos[0]
+
+	require.Equal(t, "registry.test.orders", info.Name)
+	require.Equal(t, 2, info.Version)
+	require.True(t, info.Options.GlobalID)
+	require.Equal(t, map[string]any{"team": "fulfillment"}, info.Metadata)
+	require.Equal(t, []ValueInfo{
+		{Index: 0, Type: "context.Context", Context: true},
+		{Index: 1, Type: "*registry.order"},
+		{Index: 2, Type: "map[string]float64"},
+	}, info.Args)
+	require.Equal(t, []ValueInfo{
+		{Index: 0, Type: "[]registry.order"},
+		{Index: 1, Type: "error", Error: true},
+	}, info.Results)
+
+	// Round trip through JSON to check the exported form.
+	data, err := json.Marshal(info.ArgsSchema)
+	require.NoError(t, err)
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	require.Equal(t, SchemaDialect, schema["$schema"])
+	require.Equal(t, "array", schema["type"])
+	items := schema["prefixItems"].([]any)
+	require.Len(t, items, 2)
+	require.Equal(t, "#/$defs/registry.order", items[0].(map[string]any)["$ref"])
+	require.Equal(t, map[string]any{
+		"type":                 "object",
+		"additionalProperties": map[string]any{"type": "number"},
+	}, items[1])
+
+	def := schema["$defs"].(map[string]any)["registry.order"].(map[string]any)
+	require.Equal(t, map[string]any{
+		"id":     map[string]any{"type": "string"},
+		"qty":    map[string]any{"type": "integer", "minimum": float64(0)},
+		"Placed": map[string]any{"type": "string", "format": "date-time"},
+		"parent": map[string]any{"$ref": "#/$defs/registry.order"},
+		"Notes":  map[string]any{"type": "string", "contentEncoding": "base64"},
+	}, def["properties"])
+
+	results := info.ResultsSchema["prefixItems"].([]any)
+	require.Equal(t, Schema{"type": "array", "items": Schema{"$ref": "#/$defs/registry.order"}}, results[0])
+}
+
+func TestDescribe_Proto(t *testing.T) {
+	ep, err := NewEndpoint(reflect.ValueOf(func(context.Context, *sequinv1.RunMetadata, *wrapperspb.Int64Value) (
+		*timestamppb.Timestamp, error) {
+		return nil, nil
+	}))
+	require.NoError(t, err)
+	info := Describe(ep)
+
+	items := info.ArgsSchema["prefixItems"].([]any)
+	require.Equal(t, Schema{"$ref": "#/$defs/arg0net.sequin.v1.RunMetadata"}, items[0])
+	require.Equal(t, Schema{"type": []string{"integer", "string"}}, items[1])
+
+	defs := info.ArgsSchema["$defs"].(map[string]any)
+	props := defs["arg0net.sequin.v1.RunMetadata"].(Schema)["properties"].(map[string]any)
+	require.Equal(t, Schema{"type": "string", "format": "date-time"}, props["submittedAt"])
+	require.Equal(t, Schema{"type": "object", "additionalProperties": Schema{"type": "string"}}, props["labels"])
+	require.Equal(t, Schema{"type": "array", "items": Schema{"$ref": "#/$defs/arg0net.sequin.v1.Compensation"}},
+		props["compensations"])
+	require.NotContains(t, props, "submitted_at")
+	require.Contains(t, defs, "google.rpc.Status")
+
+	results := info.ResultsSchema["prefixItems"].([]any)
+	require.Equal(t, Schema{"type": "string", "format": "date-time"}, results[0])
+}
@@ -3,8 +3,11 @@ package registry
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 	"runtime"
+
+	"github.com/vgough/sequin/internal"
 )
 
 var ContextType = reflect.TypeFor[context.Context]()
@@ -15,11 +18,21 @@ var ErrEndpointNotFound = errors.New("endpoint not found")
 // Endpoint stores information about a registered function.
 type Endpoint struct {
 	Name          string
+	Version       int            // Version of the endpoint implementation.
+	Aliases       []string       // Previous names the endpoint answers to.
 	LocalFN       reflect.Value  // Local function to call.
 	ContextIndex  int            // Index of the context.Context argument.
 	InputTypes    []reflect.Type // Argument types of the function.
 	OutputTypes   []reflect.Type // Return types of the function.
-	MethodBinding reflect.Value  // Fixed method to bind to calls.
+	MethodBinding reflect.Value  // Fixed receiver to bind to calls.
+
+	// MethodProvider resolves the receiver of calls at execution time.
+	// It is used when there is no MethodBinding.
+	MethodProvider func(context.Context) (reflect.Value, error)
+
+	// InjectedArgs marks arguments which are provided by the registry at
+	// execution time, by index. See Registry.Resolve.
+	InjectedArgs []bool
 
 	Metadata map[string]interface{} // Arbitrary metadata.
 }
@@ -72,54 +85,115 @@ func NewEndpoint(fn reflect.Value) (*Endpoint, error) {
 	return ep, nil
 }
 
-// Register adds an endpoint to the global registry.
-func Register(ep *Endpoint) error {
-	if ep.Name == "" {
-		return errors.New("endpoint name cannot be empty")
-	}
-	if _, ok := registry[ep.Name]; ok {
-		return errors.New("endpoint already registered")
-	}
+func (ep *Endpoint) GetContext(args []reflect.Value) context.Context {
+	return args[ep.ContextIndex].Interface().(context.Context)
+}
 
-	registry[ep.Name] = ep
-	return nil
+func (ep *Endpoint) SetContext(ctx context.Context, args []reflect.Value) {
+	args[ep.ContextIndex] = reflect.ValueOf(&ctx).Elem()
 }
 
-// RegisteredEndpoints returns a list of names of all registered endpoints.
-func RegisteredEndpoints() []string {
-	endpoints := make([]string, 0, len(registry))
-	for name := range registry {
-		endpoints = append(endpoints, name)
-	}
-	return endpoints
+// MakeError returns a slice of reflect.Value with the error value set.
+func (ep *Endpoint) MakeError(err error) []reflect.Value {
+	return MakeError(err, ep.OutputTypes)
 }
 
-// GetEndpoint returns the endpoint with the given name.
-// Returns nil if the endpoint is not found.
-func GetEndpoint(name string) *Endpoint {
-	return registry[name]
+// IsBound returns true if the arguments before the context are bound to a
+// receiver, rather than passed by the caller.
+func (ep *Endpoint) IsBound() bool {
+	return ep.ContextIndex > 0 &&
+		(ep.MethodBinding.IsValid() || ep.MethodProvider != nil)
 }
 
-var registry = map[string]*Endpoint{}
+// IsInjected returns true if the argument at index i is provided at execution
+// time, rather than passed by the caller. Injected arguments are not encoded.
+func (ep *Endpoint) IsInjected(i int) bool {
+	if i < len(ep.InjectedArgs) && ep.InjectedArgs[i] {
+		return true
+	}
+	return i < ep.ContextIndex && ep.IsBound()
+}
 
-func (ep *Endpoint) GetContext(args []reflect.Value) context.Context {
-	return args[ep.ContextIndex].Interface().(context.Context)
+// NumArgs returns the number of arguments passed by callers, which excludes
+// context and injected arguments.
+func (ep *Endpoint) NumArgs() int {
+	var n int
+	for i, t := range ep.InputTypes {
+		if t != ContextType && !ep.IsInjected(i) {
+			n++
+		}
+	}
+	return n
 }
 
-func (ep *Endpoint) SetContext(ctx context.Context, args []reflect.Value) {
-	args[ep.ContextIndex] = reflect.ValueOf(ctx)
+// ArgNames returns the names of the arguments passed by callers, in order, or
+// nil if the endpoint has no argument names.
+func (ep *Endpoint) ArgNames() []string {
+	names, _ := ep.Metadata[internal.ArgNames].([]string)
+	return names
 }
 
-// MakeError returns a slice of reflect.Value with the error value set.
-func (ep *Endpoint) MakeError(err error) []reflect.Value {
-	return MakeError(err, ep.OutputTypes)
+// EncodeArgs encodes the arguments of a call.
+// Context and injected arguments are not encoded, and are left empty.
+func (ep *Endpoint) EncodeArgs(args []reflect.Value) ([][]byte, error) {
+	data := make([][]byte, len(args))
+	for i, arg := range args {
+		if ep.IsInjected(i) {
+			continue
+		}
+		d, err := internal.EncodeValue(arg)
+		if err != nil {
+			return nil, err
+		}
+		data[i] = d
+	}
+	return data, nil
+}
+
+// DecodeArgs decodes arguments encoded by EncodeArgs.
+// Context and injected arguments are left unset.
+func (ep *Endpoint) DecodeArgs(data [][]byte) ([]reflect.Value, error) {
+	if len(data) != len(ep.InputTypes) {
+		return nil, fmt.Errorf("%s expects %d arguments, got %d", ep.Name, len(ep.InputTypes), len(data))
+	}
+	args := make([]reflect.Value, len(data))
+	for i, d := range data {
+		if ep.IsInjected(i) {
+			continue
+		}
+		arg, err := internal.DecodeValue(d, ep.InputTypes[i])
+		if err != nil {
+			return nil, err
+		}
+		args[i] = arg
+	}
+	return args, nil
 }
 
 // Exec calls the local function with the given context and arguments.
+// Bound arguments are replaced by the endpoint's receiver.
 func (ep *Endpoint) Exec(args []reflect.Value) []reflect.Value {
+	if err := ep.bind(args); err != nil {
+		return ep.MakeError(err)
+	}
 	return ep.LocalFN.Call(args)
 }
 
+func (ep *Endpoint) bind(args []reflect.Value) error {
+	switch {
+	case !ep.IsBound():
+	case ep.MethodBinding.IsValid():
+		args[0] = ep.MethodBinding
+	default:
+		recv, err := ep.MethodProvider(ep.GetContext(args))
+		if err != nil {
+			return fmt.Errorf("resolving receiver for %s: %w", ep.Name, err)
+		}
+		args[0] = recv
+	}
+	return nil
+}
+
 func (ep *Endpoint) GetError(results []reflect.Value) error {
 	if len(results) == 0 {
 		return nil
@@ -136,7 +210,7 @@ func MakeError(err error, outputTypes []reflect.Type) []reflect.Value {
 	out := make([]reflect.Value, len(outputTypes))
 	for i, ot := range outputTypes {
 		if ot == ErrorType {
-			out[i] = reflect.ValueOf(err)
+			out[i] = reflect.ValueOf(&err).Elem()
 		} else {
 			out[i] = reflect.Zero(ot)
 		}
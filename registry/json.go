@@ -0,0 +1,167 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/vgough/sequin/internal"
+)
+
+// UnmarshalArgs decodes the arguments of a call from JSON.
+//
+// The arguments are either a JSON array in the order of the arguments passed
+// by callers, or a JSON object of arguments by name, if the endpoint has
+// argument names. Missing named arguments are left as zero values.
+// Protobuf messages use the protobuf JSON mapping. Context and injected
+// arguments are left unset.
+func (ep *Endpoint) UnmarshalArgs(data []byte) ([]reflect.Value, error) {
+	var raw []json.RawMessage
+	switch trimmed := strings.TrimSpace(string(data)); {
+	case strings.HasPrefix(trimmed, "{"):
+		var err error
+		if raw, err = namedArgs(ep, data); err != nil {
+			return nil, err
+		}
+	default:
+		// Empty input is treated as an empty list.
+		if trimmed == "" {
+			data = []byte("[]")
+		}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("arguments must be a JSON array or object: %w", err)
+		}
+		if len(raw) != ep.NumArgs() {
+			return nil, fmt.Errorf("%s expects %d arguments, got %d", ep.Name, ep.NumArgs(), len(raw))
+		}
+	}
+
+	args := make([]reflect.Value, len(ep.InputTypes))
+	var n int
+	for i, t := range ep.InputTypes {
+		if t == ContextType || ep.IsInjected(i) {
+			continue
+		}
+		v := reflect.New(t)
+		if n < len(raw) && raw[n] != nil {
+			if err := unmarshal(raw[n], v); err != nil {
+				return nil, fmt.Errorf("argument %d: %w", n, err)
+			}
+		}
+		args[i] = v.Elem()
+		n++
+	}
+	return args, nil
+}
+
+// namedArgs orders a JSON object of arguments using the endpoint's argument
+// names. Missing arguments are left nil.
+func namedArgs(ep *Endpoint, data []byte) ([]json.RawMessage, error) {
+	names := ep.ArgNames()
+	if names == nil {
+		return nil, fmt.Errorf("%s does not have argument names, arguments must be a JSON array", ep.Name)
+	}
+	var byName map[string]json.RawMessage
+	if err := json.Unmarshal(data, &byName); err != nil {
+		return nil, err
+	}
+	raw := make([]json.RawMessage, len(names))
+	for i, name := range names {
+		raw[i] = byName[name]
+		delete(byName, name)
+	}
+	for name := range byName {
+		return nil, fmt.Errorf("%s has no argument named %q", ep.Name, name)
+	}
+	return raw, nil
+}
+
+// MarshalResults encodes the results of a call, other than the error, as JSON.
+// Protobuf messages use the protobuf JSON mapping.
+func (ep *Endpoint) MarshalResults(out []reflect.Value) ([]json.RawMessage, error) {
+	var results []json.RawMessage
+	var n int
+	for i, t := range ep.OutputTypes {
+		if t == ErrorType {
+			continue
+		}
+		data, err := marshal(out[i])
+		if err != nil {
+			return nil, fmt.Errorf("result %d: %w", n, err)
+		}
+		results = append(results, data)
+		n++
+	}
+	return results, nil
+}
+
+// MarshalArgs encodes the arguments passed by the caller of a call as JSON,
+// in the form accepted by UnmarshalArgs. Context and injected arguments are
+// omitted.
+func (ep *Endpoint) MarshalArgs(args []reflect.Value) ([]json.RawMessage, error) {
+	raw := []json.RawMessage{}
+	var n int
+	for i, t := range ep.InputTypes {
+		if t == ContextType || ep.IsInjected(i) {
+			continue
+		}
+		data, err := marshal(args[i])
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", n, err)
+		}
+		raw = append(raw, data)
+		n++
+	}
+	return raw, nil
+}
+
+// UnmarshalResults decodes results encoded by MarshalResults. The error
+// result is left nil.
+func (ep *Endpoint) UnmarshalResults(raw []json.RawMessage) ([]reflect.Value, error) {
+	out := make([]reflect.Value, len(ep.OutputTypes))
+	var n int
+	for i, t := range ep.OutputTypes {
+		v := reflect.New(t)
+		if t != ErrorType {
+			if n >= len(raw) {
+				return nil, fmt.Errorf("%s returns %d results, got %d", ep.Name, n+1, len(raw))
+			}
+			if err := unmarshal(raw[n], v); err != nil {
+				return nil, fmt.Errorf("result %d: %w", n, err)
+			}
+			n++
+		}
+		out[i] = v.Elem()
+	}
+	if n != len(raw) {
+		return nil, fmt.Errorf("%s returns %d results, got %d", ep.Name, n, len(raw))
+	}
+	return out, nil
+}
+
+// unmarshal decodes JSON into the value pointed to by ptr.
+func unmarshal(data []byte, ptr reflect.Value) error {
+	if internal.IsProtoMessage(ptr.Type().Elem()) {
+		msg := reflect.New(ptr.Type().Elem().Elem())
+		if err := protojson.Unmarshal(data, msg.Interface().(proto.Message)); err != nil {
+			return err
+		}
+		ptr.Elem().Set(msg)
+		return nil
+	}
+	return json.Unmarshal(data, ptr.Interface())
+}
+
+func marshal(v reflect.Value) ([]byte, error) {
+	if internal.IsProtoMessage(v.Type()) {
+		if v.IsNil() {
+			return []byte("null"), nil
+		}
+		return protojson.Marshal(v.Interface().(proto.Message))
+	}
+	return json.Marshal(v.Interface())
+}
@@ -0,0 +1,44 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxNameLength is the maximum length of an explicit endpoint name.
+const MaxNameLength = 200
+
+// ValidateName checks that an explicit endpoint name is a stable identifier.
+//
+// Names consist of letters, digits and underscores, in segments separated by
+// single '.', '/' or '-' characters. Names must begin with a letter.
+func ValidateName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("endpoint name cannot be empty")
+	case len(name) > MaxNameLength:
+		return fmt.Errorf("endpoint name %q exceeds %d characters", name, MaxNameLength)
+	case !isLetter(name[0]):
+		return fmt.Errorf("endpoint name %q must begin with a letter", name)
+	case strings.ContainsAny(name[len(name)-1:], "./-"):
+		return fmt.Errorf("endpoint name %q must not end with a separator", name)
+	}
+
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case isLetter(c), c >= '0' && c <= '9', c == '_':
+		case c == '.', c == '/', c == '-':
+			if strings.ContainsAny(name[i-1:i], "./-") {
+				return fmt.Errorf("endpoint name %q contains consecutive separators", name)
+			}
+		default:
+			return fmt.Errorf("endpoint name %q contains invalid character %q", name, c)
+		}
+	}
+	return nil
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,32 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{
+		"a",
+		"billing.ChargeCard",
+		"example.com/billing/charge-card.v2",
+		"step_1",
+	} {
+		require.NoError(t, ValidateName(name), name)
+	}
+
+	for _, name := range []string{
+		"",
+		"1step",
+		"billing.",
+		"billing..charge",
+		"pkg.(*Type).Method",
+		"pkg.glob..func1",
+		"charge card",
+		strings.Repeat("a", MaxNameLength+1),
+	} {
+		require.Error(t, ValidateName(name), name)
+	}
+}
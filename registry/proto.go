@@ -0,0 +1,75 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/vgough/sequin/internal"
+)
+
+// UnpackArgs converts arguments packed as protobuf Any messages, such as those
+// of a FuncOperation, into call arguments.
+//
+// Each argument passed by callers must be a protobuf message type, and the
+// Any messages must match those types in order. Context and injected
+// arguments are left unset.
+func (ep *Endpoint) UnpackArgs(packed []*anypb.Any) ([]reflect.Value, error) {
+	args := make([]reflect.Value, len(ep.InputTypes))
+	var n int
+	for i, t := range ep.InputTypes {
+		if t == ContextType || ep.IsInjected(i) {
+			continue
+		}
+		if !internal.IsProtoMessage(t) {
+			return nil, fmt.Errorf("%s: argument %d has type %v, which is not a protobuf message",
+				ep.Name, i, t)
+		}
+		if n >= len(packed) {
+			n++
+			continue
+		}
+
+		msg := reflect.New(t.Elem()).Interface().(proto.Message)
+		if a := packed[n]; !a.MessageIs(msg) {
+			return nil, fmt.Errorf("%s: argument %d must be %s, got %s",
+				ep.Name, i, msg.ProtoReflect().Descriptor().FullName(), a.GetTypeUrl())
+		} else if err := a.UnmarshalTo(msg); err != nil {
+			return nil, fmt.Errorf("%s: argument %d: %w", ep.Name, i, err)
+		}
+		args[i] = reflect.ValueOf(msg)
+		n++
+	}
+	if n != len(packed) {
+		return nil, fmt.Errorf("%s expects %d arguments, got %d", ep.Name, n, len(packed))
+	}
+	return args, nil
+}
+
+// PackResults converts the results of a call into protobuf Any messages.
+// Each result, other than the error, must be a protobuf message type.
+// Nil messages are packed as empty messages.
+func (ep *Endpoint) PackResults(results []reflect.Value) ([]*anypb.Any, error) {
+	var packed []*anypb.Any
+	for i, t := range ep.OutputTypes {
+		if t == ErrorType {
+			continue
+		}
+		if !internal.IsProtoMessage(t) {
+			return nil, fmt.Errorf("%s: result %d has type %v, which is not a protobuf message",
+				ep.Name, i, t)
+		}
+		v := results[i]
+		if v.IsNil() {
+			v = reflect.New(t.Elem())
+		}
+		a, err := anypb.New(v.Interface().(proto.Message))
+		if err != nil {
+			return nil, fmt.Errorf("%s: result %d: %w", ep.Name, i, err)
+		}
+		packed = append(packed, a)
+	}
+	return packed, nil
+}
@@ -0,0 +1,50 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Provider returns a value to inject into calls at execution time.
+type Provider func(context.Context) (reflect.Value, error)
+
+// Provide registers a provider for arguments of type t, for endpoints which
+// mark that type as injected.
+func (r *Registry) Provide(t reflect.Type, p Provider) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.providers[t]; ok {
+		return fmt.Errorf("provider for type %v already registered", t)
+	}
+	r.providers[t] = p
+	return nil
+}
+
+// GetProvider returns the provider for type t, or nil if there is none.
+func (r *Registry) GetProvider(t reflect.Type) Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.providers[t]
+}
+
+// Resolve fills in the injected arguments of a call using the registered
+// providers. The context argument must already be set.
+func (r *Registry) Resolve(ep *Endpoint, args []reflect.Value) error {
+	for i, injected := range ep.InjectedArgs {
+		if !injected {
+			continue
+		}
+		t := ep.InputTypes[i]
+		p := r.GetProvider(t)
+		if p == nil {
+			return fmt.Errorf("%s: no provider registered for type %v", ep.Name, t)
+		}
+		v, err := p(ep.GetContext(args))
+		if err != nil {
+			return fmt.Errorf("%s: providing %v: %w", ep.Name, t, err)
+		}
+		args[i] = v
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Registry is a set of endpoints which can be resolved by name.
+// It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	endpoints map[string]*Endpoint
+	aliases   map[string]*Endpoint
+	providers map[reflect.Type]Provider
+}
+
+// Default is the registry used by sequin.Register, and by runtimes which are
+// not configured with a specific registry.
+var Default = New()
+
+// New returns an empty registry.
+func New() *Registry {
+	return &Registry{
+		endpoints: make(map[string]*Endpoint),
+		aliases:   make(map[string]*Endpoint),
+		providers: make(map[reflect.Type]Provider),
+	}
+}
+
+// Register adds an endpoint to the registry.
+// The endpoint is registered under its name and all of its aliases.
+func (r *Registry) Register(ep *Endpoint) error {
+	if ep.Name == "" {
+		return errors.New("endpoint name cannot be empty")
+	}
+	if names := ep.ArgNames(); names != nil && len(names) != ep.NumArgs() {
+		return fmt.Errorf("endpoint %s has %d argument names, but takes %d arguments",
+			ep.Name, len(names), ep.NumArgs())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range append([]string{ep.Name}, ep.Aliases...) {
+		if r.lookup(name) != nil {
+			return fmt.Errorf("endpoint %q already registered", name)
+		}
+	}
+
+	r.endpoints[ep.Name] = ep
+	for _, alias := range ep.Aliases {
+		r.aliases[alias] = ep
+	}
+	return nil
+}
+
+// Unregister removes the endpoint with the given name, along with its aliases.
+// Returns false if the endpoint is not found.
+func (r *Registry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ep, ok := r.endpoints[name]
+	if !ok {
+		return false
+	}
+	delete(r.endpoints, name)
+	for _, alias := range ep.Aliases {
+		delete(r.aliases, alias)
+	}
+	return true
+}
+
+// RegisteredEndpoints returns a sorted list of names of all registered
+// endpoints. Aliases are not included.
+func (r *Registry) RegisteredEndpoints() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	endpoints := make([]string, 0, len(r.endpoints))
+	for name := range r.endpoints {
+		endpoints = append(endpoints, name)
+	}
+	slices.Sort(endpoints)
+	return endpoints
+}
+
+// GetEndpoint returns the endpoint with the given name or alias.
+// Returns nil if the endpoint is not found.
+func (r *Registry) GetEndpoint(name string) *Endpoint {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lookup(name)
+}
+
+func (r *Registry) lookup(name string) *Endpoint {
+	if ep, ok := r.endpoints[name]; ok {
+		return ep
+	}
+	return r.aliases[name]
+}
+
+// Register adds an endpoint to the default registry.
+func Register(ep *Endpoint) error {
+	return Default.Register(ep)
+}
+
+// RegisteredEndpoints returns a list of names of all endpoints in the default
+// registry.
+func RegisteredEndpoints() []string {
+	return Default.RegisteredEndpoints()
+}
+
+// GetEndpoint returns the endpoint with the given name from the default
+// registry. Returns nil if the endpoint is not found.
+func GetEndpoint(name string) *Endpoint {
+	return Default.GetEndpoint(name)
+}
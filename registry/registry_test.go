@@ -0,0 +1,33 @@
+package registry
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	r := New()
+	ep, err := NewEndpoint(reflect.ValueOf(noop))
+	require.NoError(t, err)
+	ep.Name = "registry.test.noop"
+	ep.Aliases = []string{"registry.test.old"}
+
+	require.NoError(t, r.Register(ep))
+	require.Error(t, r.Register(ep))
+	require.Equal(t, []string{"registry.test.noop"}, r.RegisteredEndpoints())
+	require.Same(t, ep, r.GetEndpoint("registry.test.noop"))
+	require.Same(t, ep, r.GetEndpoint("registry.test.old"))
+	require.Nil(t, Default.GetEndpoint("registry.test.noop"))
+
+	require.True(t, r.Unregister("registry.test.noop"))
+	require.False(t, r.Unregister("registry.test.noop"))
+	require.Empty(t, r.RegisteredEndpoints())
+	require.Nil(t, r.GetEndpoint("registry.test.old"))
+}
+
+func noop(context.Context) error {
+	return nil
+}
@@ -0,0 +1,292 @@
+package registry
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/vgough/sequin/internal"
+)
+
+// SchemaDialect is the JSON Schema dialect of generated schemas.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document.
+type Schema map[string]any
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// TypeSchema returns a JSON Schema describing the JSON encoding of values of
+// type t, following the conventions of encoding/json. Protobuf messages follow
+// the protobuf JSON mapping.
+func TypeSchema(t reflect.Type) Schema {
+	g := newSchemaGen()
+	return g.document(g.schema(t))
+}
+
+// tupleSchema returns a JSON Schema for an array holding values of the given
+// types in order.
+func tupleSchema(types []reflect.Type) Schema {
+	g := newSchemaGen()
+	items := make([]any, len(types))
+	for i, t := range types {
+		items[i] = g.schema(t)
+	}
+	return g.document(Schema{
+		"type":        "array",
+		"prefixItems": items,
+		"items":       false,
+		"minItems":    len(types),
+	})
+}
+
+// schemaGen generates schemas, placing named struct types and protobuf
+// messages in $defs so that they can be shared and may be recursive.
+type schemaGen struct {
+	defs       map[string]any
+	names      map[reflect.Type]string
+	protoNames map[protoreflect.FullName]string
+}
+
+func newSchemaGen() *schemaGen {
+	return &schemaGen{
+		defs:       make(map[string]any),
+		names:      make(map[reflect.Type]string),
+		protoNames: make(map[protoreflect.FullName]string),
+	}
+}
+
+// document returns a standalone schema document, including any definitions.
+func (g *schemaGen) document(s Schema) Schema {
+	doc := Schema{"$schema": SchemaDialect}
+	for k, v := range s {
+		doc[k] = v
+	}
+	if len(g.defs) > 0 {
+		doc["$defs"] = g.defs
+	}
+	return doc
+}
+
+func (g *schemaGen) schema(t reflect.Type) Schema {
+	switch {
+	case internal.IsProtoMessage(t):
+		msg := reflect.Zero(t).Interface().(proto.Message)
+		return g.messageSchema(msg.ProtoReflect().Descriptor())
+	case t == timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case t.Implements(jsonMarshalerType), reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return Schema{}
+	case t.Implements(textMarshalerType), reflect.PointerTo(t).Implements(textMarshalerType):
+		return Schema{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Schema{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return Schema{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return Schema{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Array:
+		return Schema{
+			"type":     "array",
+			"items":    g.schema(t.Elem()),
+			"minItems": t.Len(),
+			"maxItems": t.Len(),
+		}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+	// Interfaces may hold any value.
+	return Schema{}
+}
+
+func (g *schemaGen) structSchema(t reflect.Type) Schema {
+	if t.Name() == "" {
+		return g.structProperties(t)
+	}
+	name, ok := g.names[t]
+	if !ok {
+		name = g.defName(t.String())
+		g.names[t] = name
+		g.defs[name] = Schema{} // placeholder for recursive references.
+		g.defs[name] = g.structProperties(t)
+	}
+	return Schema{"$ref": "#/$defs/" + name}
+}
+
+// defName returns a unique definition name for a named type.
+func (g *schemaGen) defName(typeName string) string {
+	base := strings.NewReplacer("/", ".", "*", "").Replace(typeName)
+	name := base
+	for i := 2; ; i++ {
+		if _, ok := g.defs[name]; !ok {
+			return name
+		}
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+}
+
+func (g *schemaGen) structProperties(t reflect.Type) Schema {
+	props := make(map[string]any)
+	g.addFields(t, props)
+	return Schema{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+}
+
+// addFields adds the JSON fields of a struct to props, including the fields
+// of embedded structs.
+func (g *schemaGen) addFields(t reflect.Type, props map[string]any) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			g.addFields(ft, props)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128:
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+	}
+}
+
+// wellKnownSchemas are the schemas of protobuf well-known types which have a
+// special JSON mapping.
+var wellKnownSchemas = map[protoreflect.FullName]Schema{
+	"google.protobuf.Timestamp":   {"type": "string", "format": "date-time"},
+	"google.protobuf.Duration":    {"type": "string"},
+	"google.protobuf.FieldMask":   {"type": "string"},
+	"google.protobuf.Struct":      {"type": "object"},
+	"google.protobuf.ListValue":   {"type": "array"},
+	"google.protobuf.Value":       {},
+	"google.protobuf.Any":         {"type": "object", "required": []string{"@type"}},
+	"google.protobuf.Empty":       {"type": "object"},
+	"google.protobuf.BoolValue":   {"type": "boolean"},
+	"google.protobuf.StringValue": {"type": "string"},
+	"google.protobuf.BytesValue":  {"type": "string", "contentEncoding": "base64"},
+	"google.protobuf.DoubleValue": {"type": []string{"number", "string"}},
+	"google.protobuf.FloatValue":  {"type": []string{"number", "string"}},
+	"google.protobuf.Int32Value":  {"type": "integer"},
+	"google.protobuf.UInt32Value": {"type": "integer", "minimum": 0},
+	"google.protobuf.Int64Value":  {"type": []string{"integer", "string"}},
+	"google.protobuf.UInt64Value": {"type": []string{"integer", "string"}},
+}
+
+// messageSchema returns the schema of a protobuf message, using the field
+// names of the protobuf JSON mapping. Fields may also be given by their
+// original names, so other properties are allowed.
+func (g *schemaGen) messageSchema(md protoreflect.MessageDescriptor) Schema {
+	if s, ok := wellKnownSchemas[md.FullName()]; ok {
+		return s
+	}
+	name, ok := g.protoNames[md.FullName()]
+	if !ok {
+		name = g.defName(string(md.FullName()))
+		g.protoNames[md.FullName()] = name
+		g.defs[name] = Schema{} // placeholder for recursive references.
+
+		props := make(map[string]any)
+		fields := md.Fields()
+		for i := range fields.Len() {
+			fd := fields.Get(i)
+			props[fd.JSONName()] = g.fieldSchema(fd)
+		}
+		g.defs[name] = Schema{"type": "object", "properties": props}
+	}
+	return Schema{"$ref": "#/$defs/" + name}
+}
+
+// fieldSchema returns the schema of a protobuf message field.
+func (g *schemaGen) fieldSchema(fd protoreflect.FieldDescriptor) Schema {
+	switch {
+	case fd.IsMap():
+		return Schema{"type": "object", "additionalProperties": g.singularSchema(fd.MapValue())}
+	case fd.IsList():
+		return Schema{"type": "array", "items": g.singularSchema(fd)}
+	}
+	return g.singularSchema(fd)
+}
+
+// singularSchema returns the schema of a single value of a protobuf field.
+func (g *schemaGen) singularSchema(fd protoreflect.FieldDescriptor) Schema {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return Schema{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return Schema{"type": "integer"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return Schema{"type": "integer", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// 64-bit integers are encoded as strings, and either is accepted.
+		return Schema{"type": []string{"integer", "string"}}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		// Special values such as "NaN" are encoded as strings.
+		return Schema{"type": []string{"number", "string"}}
+	case protoreflect.StringKind:
+		return Schema{"type": "string"}
+	case protoreflect.BytesKind:
+		return Schema{"type": "string", "contentEncoding": "base64"}
+	case protoreflect.EnumKind:
+		if fd.Enum().FullName() == "google.protobuf.NullValue" {
+			return Schema{"type": "null"}
+		}
+		values := fd.Enum().Values()
+		names := make([]string, values.Len())
+		for i := range names {
+			names[i] = string(values.Get(i).Name())
+		}
+		// Enums are encoded by name, and numbers are also accepted.
+		return Schema{"anyOf": []any{
+			Schema{"type": "string", "enum": names},
+			Schema{"type": "integer"},
+		}}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return g.messageSchema(fd.Message())
+	}
+	return Schema{}
+}
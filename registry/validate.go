@@ -0,0 +1,154 @@
+package registry
+
+import (
+	"encoding"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/vgough/sequin/internal"
+)
+
+var (
+	gobEncoderType    = reflect.TypeFor[gob.GobEncoder]()
+	binaryMarshalType = reflect.TypeFor[encoding.BinaryMarshaler]()
+	textMarshalType   = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// ValidateOpt is an option for Validate.
+type ValidateOpt func(*checker)
+
+// StrictInterfaces reports interface types as errors. Interface values can
+// only be decoded if their concrete types have been registered with
+// gob.Register, which can't be checked, so they are allowed by default.
+func StrictInterfaces() ValidateOpt {
+	return func(c *checker) {
+		c.strictInterfaces = true
+	}
+}
+
+// Validate checks that the arguments and results of the endpoint can be
+// encoded. Context, injected and error values are not encoded, so are not
+// checked.
+//
+// The returned error describes every type which can't be encoded, along with
+// the path to it, such as "args[1].Items[].Handler".
+func Validate(ep *Endpoint, opts ...ValidateOpt) error {
+	c := &checker{
+		passed: map[reflect.Type]bool{},
+		active: map[reflect.Type]bool{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	var errs []error
+	for i, t := range ep.InputTypes {
+		if t == ContextType || ep.IsInjected(i) {
+			continue
+		}
+		errs = append(errs, c.check(t, fmt.Sprintf("args[%d]", i))...)
+	}
+	for i, t := range ep.OutputTypes {
+		if t == ErrorType {
+			continue
+		}
+		errs = append(errs, c.check(t, fmt.Sprintf("results[%d]", i))...)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: %w", ep.Name, errors.Join(errs...))
+	}
+	return nil
+}
+
+// Validate checks all endpoints in the registry. See Validate for details.
+func (r *Registry) Validate(opts ...ValidateOpt) error {
+	var errs []error
+	for _, name := range r.RegisteredEndpoints() {
+		if ep := r.GetEndpoint(name); ep != nil {
+			errs = append(errs, Validate(ep, opts...))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checker checks whether types can be encoded with gob.
+type checker struct {
+	strictInterfaces bool
+
+	// passed holds types which have been checked without errors. Types with
+	// errors are checked again, so that every path to them is reported.
+	passed map[reflect.Type]bool
+	// active holds types which are being checked, to stop recursive types.
+	active map[reflect.Type]bool
+}
+
+// check returns errors for any part of type t which can't be encoded with gob.
+func (c *checker) check(t reflect.Type, path string) []error {
+	if c.passed[t] || c.active[t] || hasMarshaler(t) {
+		return nil
+	}
+	c.active[t] = true
+	errs := c.checkKind(t, path)
+	delete(c.active, t)
+	if len(errs) == 0 {
+		c.passed[t] = true
+	}
+	return errs
+}
+
+func (c *checker) checkKind(t reflect.Type, path string) []error {
+	fail := func(reason string) []error {
+		return []error{fmt.Errorf("%s: type %v %s", path, t, reason)}
+	}
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return fail("can't be encoded")
+	case reflect.Interface:
+		if c.strictInterfaces {
+			return fail("is an interface, which can't be decoded without registering concrete types")
+		}
+	case reflect.Pointer:
+		return c.check(t.Elem(), path)
+	case reflect.Array, reflect.Slice:
+		return c.check(t.Elem(), path+"[]")
+	case reflect.Map:
+		return append(c.check(t.Key(), path+"[key]"),
+			c.check(t.Elem(), path+"[value]")...)
+	case reflect.Struct:
+		var errs []error
+		var exported int
+		for i := range t.NumField() {
+			f := t.Field(i)
+			switch f.Type.Kind() {
+			case reflect.Chan, reflect.Func:
+				// Ignored by gob, like unexported fields.
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+			exported++
+			errs = append(errs, c.check(f.Type, path+"."+f.Name)...)
+		}
+		if exported == 0 {
+			return fail("has no exported fields")
+		}
+		return errs
+	}
+	return nil
+}
+
+// hasMarshaler returns true if the type is encoded using its own methods.
+func hasMarshaler(t reflect.Type) bool {
+	if internal.IsProtoMessage(t) {
+		return true
+	}
+	pt := reflect.PointerTo(t)
+	for _, m := range []reflect.Type{gobEncoderType, binaryMarshalType, textMarshalType} {
+		if t.Implements(m) || pt.Implements(m) {
+			return true
+		}
+	}
+	return false
+}
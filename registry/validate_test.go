@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type validConfig struct {
+	Name     string
+	Started  time.Time
+	Children []*validConfig
+	Labels   map[string][]int
+	OnChange func() // ignored by gob.
+}
+
+type invalidConfig struct {
+	Items    []invalidItem
+	Fallback *invalidItem
+	Events   map[string]chan int
+}
+
+type invalidItem struct {
+	Reader io.Reader
+	Secret opaque
+}
+
+type opaque struct {
+	n int
+}
+
+func TestValidate(t *testing.T) {
+	ep, err := NewEndpoint(reflect.ValueOf(func(context.Context, validConfig, *int) (validConfig, error) {
+		return validConfig{}, nil
+	}))
+	require.NoError(t, err)
+	require.NoError(t, Validate(ep))
+
+	ep, err = NewEndpoint(reflect.ValueOf(func(context.Context, invalidConfig, chan int) (func(), error) {
+		return nil, nil
+	}))
+	require.NoError(t, err)
+	err = Validate(ep)
+	require.Error(t, err)
+	for _, msg := range []string{
+		"args[1].Items[].Secret: type registry.opaque has no exported fields",
+		"args[1].Fallback.Secret: type registry.opaque has no exported fields",
+		"args[1].Events[value]: type chan int can't be encoded",
+		"args[2]: type chan int can't be encoded",
+		"results[0]: type func() can't be encoded",
+	} {
+		require.ErrorContains(t, err, msg)
+	}
+	require.NotContains(t, err.Error(), "io.Reader")
+	require.ErrorContains(t, Validate(ep, StrictInterfaces()),
+		"args[1].Items[].Reader: type io.Reader is an interface")
+
+	r := New()
+	ep.Name = "registry.test.invalid"
+	require.NoError(t, r.Register(ep))
+	require.ErrorContains(t, r.Validate(), "registry.test.invalid")
+}
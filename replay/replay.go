@@ -0,0 +1,292 @@
+// Package replay provides runtimes for deterministic workflow tests.
+//
+// A Recorder executes endpoint calls and records them to a golden file. A
+// Replayer serves results from that file instead of executing the calls, and
+// fails the test if the workflow's call sequence diverges from the recording.
+// This allows regression testing long workflows without running their side
+// effects.
+//
+// Only the calls made directly by the code under test are recorded. Calls made
+// by the recorded endpoints themselves are executed by the wrapped runtime,
+// since replay never executes the recorded endpoints.
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vgough/sequin"
+	"github.com/vgough/sequin/registry"
+)
+
+// ErrDiverged is returned by replayed calls that do not match the recording.
+var ErrDiverged = errors.New("replay: call sequence diverged")
+
+// Call is a single recorded endpoint call.
+//
+// Arguments and results are stored as JSON, using the protobuf JSON mapping
+// for protobuf messages, so golden files can be reviewed and edited by hand.
+// Context and injected arguments are omitted, as is the error result, which
+// is stored by its message.
+type Call struct {
+	Name    string            `json:"name"`
+	Args    []json.RawMessage `json:"args"`
+	Results []json.RawMessage `json:"results"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// New returns a Recorder when update is true, and a Replayer otherwise.
+//
+// This is intended for use with a test flag, such as:
+//
+//	var update = flag.Bool("update", false, "update golden files")
+//
+//	rt := replay.New(t, "testdata/workflow.json", local.NewServer(), *update)
+func New(t testing.TB, path string, next sequin.Runtime, update bool) sequin.Runtime {
+	if update {
+		return NewRecorder(t, path, next)
+	}
+	return NewReplayer(t, path)
+}
+
+// Recorder is a runtime that records endpoint calls to a golden file.
+type Recorder struct {
+	path string
+	next sequin.Runtime
+
+	mu    sync.Mutex
+	calls []*Call
+}
+
+var _ sequin.Runtime = &Recorder{}
+
+// NewRecorder returns a runtime which executes calls using next and records
+// them to path when the test completes.
+// If next is nil, calls are executed directly.
+func NewRecorder(t testing.TB, path string, next sequin.Runtime) *Recorder {
+	r := &Recorder{path: path, next: next}
+	t.Cleanup(func() {
+		if err := r.Save(); err != nil {
+			t.Errorf("replay: %v", err)
+		}
+	})
+	return r
+}
+
+func (r *Recorder) Exec(ep *registry.Endpoint, args []reflect.Value) []reflect.Value {
+	data, err := ep.MarshalArgs(args)
+	if err != nil {
+		return ep.MakeError(err)
+	}
+
+	// Calls are recorded in the order they are issued.
+	call := &Call{Name: ep.Name, Args: data}
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	r.mu.Unlock()
+
+	ctx := ep.GetContext(args)
+	ep.SetContext(sequin.WithRuntime(ctx, r.next), args)
+	var out []reflect.Value
+	if r.next != nil {
+		out = r.next.Exec(ep, args)
+	} else {
+		out = ep.Exec(args)
+	}
+
+	results, err := ep.MarshalResults(out)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		// Record the failure returned to the caller, so that it is replayed.
+		err = fmt.Errorf("replay: call to %s: %w", ep.Name, err)
+		call.Error = err.Error()
+		return ep.MakeError(err)
+	}
+	call.Results = results
+	if err := ep.GetError(out); err != nil {
+		call.Error = err.Error()
+	}
+	return out
+}
+
+// Calls returns the calls recorded so far.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := make([]Call, len(r.calls))
+	for i, c := range r.calls {
+		calls[i] = *c
+	}
+	return calls
+}
+
+// Save writes the recorded calls to the golden file.
+func (r *Recorder) Save() error {
+	data, err := json.MarshalIndent(r.Calls(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// Replayer is a runtime that serves endpoint results from a golden file.
+type Replayer struct {
+	t testing.TB
+
+	mu    sync.Mutex
+	calls []Call
+	next  int
+}
+
+var _ sequin.Runtime = &Replayer{}
+
+// NewReplayer returns a runtime which serves calls from the golden file at
+// path. The test fails if calls diverge from the recording, or if recorded
+// calls remain when the test completes.
+func NewReplayer(t testing.TB, path string) *Replayer {
+	calls, err := Load(path)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	r := &Replayer{t: t, calls: calls}
+	t.Cleanup(func() {
+		if err := r.Verify(); err != nil {
+			t.Error(err)
+		}
+	})
+	return r
+}
+
+// Load reads recorded calls from a golden file.
+func Load(path string) ([]Call, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var calls []Call
+	if err := json.Unmarshal(data, &calls); err != nil {
+		return nil, fmt.Errorf("invalid golden file %q: %w", path, err)
+	}
+	return calls, nil
+}
+
+func (r *Replayer) Exec(ep *registry.Endpoint, args []reflect.Value) []reflect.Value {
+	data, err := ep.MarshalArgs(args)
+	if err != nil {
+		return ep.MakeError(err)
+	}
+
+	call, err := r.match(ep, data)
+	if err != nil {
+		r.t.Error(err)
+		return ep.MakeError(err)
+	}
+
+	if call.Results == nil && call.Error != "" {
+		// The results of the call could not be recorded.
+		return ep.MakeError(errors.New(call.Error))
+	}
+	out, err := ep.UnmarshalResults(call.Results)
+	if err != nil {
+		return ep.MakeError(fmt.Errorf("replay: call to %s: %w", ep.Name, err))
+	}
+	if call.Error != "" {
+		callErr := errors.New(call.Error)
+		for i, t := range ep.OutputTypes {
+			if t == registry.ErrorType {
+				out[i] = reflect.ValueOf(&callErr).Elem()
+			}
+		}
+	}
+	return out
+}
+
+// match returns the next recorded call, if it matches the given call.
+func (r *Replayer) match(ep *registry.Endpoint, data []json.RawMessage) (*Call, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := r.next
+	if n >= len(r.calls) {
+		return nil, fmt.Errorf("%w: unexpected call #%d to %s, only %d calls recorded",
+			ErrDiverged, n+1, ep.Name, len(r.calls))
+	}
+	call := &r.calls[n]
+	if call.Name != ep.Name {
+		return nil, fmt.Errorf("%w: call #%d is to %s, expected %s",
+			ErrDiverged, n+1, ep.Name, describe(call))
+	}
+	if !equalJSON(call.Args, data) {
+		return nil, fmt.Errorf("%w: call #%d to %s has different arguments %s, expected %s",
+			ErrDiverged, n+1, ep.Name, formatArgs(data), describe(call))
+	}
+	r.next++
+	return call, nil
+}
+
+// Verify returns an error if any recorded calls have not been replayed.
+func (r *Replayer) Verify() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.next < len(r.calls) {
+		return fmt.Errorf("%w: %d of %d recorded calls were not made, next expected %s",
+			ErrDiverged, len(r.calls)-r.next, len(r.calls), describe(&r.calls[r.next]))
+	}
+	return nil
+}
+
+// describe returns a human-readable form of a recorded call.
+func describe(c *Call) string {
+	return c.Name + formatArgs(c.Args)
+}
+
+// formatArgs returns the arguments of a call in compact JSON.
+func formatArgs(args []json.RawMessage) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		var b bytes.Buffer
+		if err := json.Compact(&b, arg); err != nil {
+			parts[i] = string(arg)
+			continue
+		}
+		parts[i] = b.String()
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// equalJSON reports whether lists of JSON values are equal, ignoring
+// formatting and the order of object keys. Numbers are compared by their
+// text, so large integers are not rounded.
+func equalJSON(a, b []json.RawMessage) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		av, aErr := decodeJSON(a[i])
+		bv, bErr := decodeJSON(b[i])
+		if aErr != nil || bErr != nil || !reflect.DeepEqual(av, bv) {
+			return false
+		}
+	}
+	return true
+}
+
+func decodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	err := dec.Decode(&v)
+	return v, err
+}
@@ -0,0 +1,160 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/vgough/sequin"
+	"github.com/vgough/sequin/local"
+	"github.com/vgough/sequin/registry"
+)
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workflow.json")
+
+	t.Run("record", func(t *testing.T) {
+		rt := NewRecorder(t, path, local.NewServer())
+		ctx := sequin.WithRuntime(context.Background(), rt)
+
+		out, err := workflow(ctx, 3)
+		require.NoError(t, err)
+		require.Equal(t, "3 is odd, 4 is even", out)
+
+		_, err = workflow(ctx, -1)
+		require.EqualError(t, err, "negative values not supported")
+		require.Len(t, rt.Calls(), 3)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		before := stepCount.Load()
+		rt := NewReplayer(t, path)
+		ctx := sequin.WithRuntime(context.Background(), rt)
+
+		out, err := workflow(ctx, 3)
+		require.NoError(t, err)
+		require.Equal(t, "3 is odd, 4 is even", out)
+
+		_, err = workflow(ctx, -1)
+		require.EqualError(t, err, "negative values not supported")
+		require.NoError(t, rt.Verify())
+		require.Equal(t, before, stepCount.Load(), "replay must not execute steps")
+	})
+
+	t.Run("golden", func(t *testing.T) {
+		calls, err := Load(path)
+		require.NoError(t, err)
+		require.Len(t, calls, 3)
+		require.Equal(t, []json.RawMessage{json.RawMessage("3")}, calls[0].Args)
+		require.Equal(t, []json.RawMessage{json.RawMessage("false")}, calls[0].Results)
+		require.Equal(t, "negative values not supported", calls[2].Error)
+
+		// Golden files can be edited by hand.
+		calls[0].Results[0] = json.RawMessage("true")
+		data, err := json.Marshal(calls)
+		require.NoError(t, err)
+		edited := filepath.Join(t.TempDir(), "edited.json")
+		require.NoError(t, os.WriteFile(edited, data, 0o644))
+
+		ctx := sequin.WithRuntime(context.Background(), NewReplayer(t, edited))
+		out, err := workflow(ctx, 3)
+		require.NoError(t, err)
+		require.Equal(t, "3 is even, 4 is even", out)
+		_, err = workflow(ctx, -1)
+		require.EqualError(t, err, "negative values not supported")
+	})
+
+	t.Run("diverged", func(t *testing.T) {
+		tb := &captureTB{TB: t}
+		rt := NewReplayer(tb, path)
+		ctx := sequin.WithRuntime(context.Background(), rt)
+
+		_, err := workflow(ctx, 5)
+		require.ErrorIs(t, err, ErrDiverged)
+		require.NotEmpty(t, tb.errors)
+		require.Contains(t, tb.errors[0], "different arguments")
+		require.ErrorIs(t, rt.Verify(), ErrDiverged)
+	})
+}
+
+// workflow is the code under test, calling registered steps.
+func workflow(ctx context.Context, n int) (string, error) {
+	var parts []string
+	for _, v := range []int{n, n + 1} {
+		even, err := IsEven(ctx, v)
+		if err != nil {
+			return "", err
+		}
+		if even {
+			parts = append(parts, fmt.Sprintf("%d is even", v))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d is odd", v))
+		}
+	}
+	return parts[0] + ", " + parts[1], nil
+}
+
+var stepCount atomic.Int32
+
+var IsEven = sequin.Register(isEven)
+
+func isEven(_ context.Context, in int) (bool, error) {
+	stepCount.Add(1)
+	if in < 0 {
+		return false, errors.New("negative values not supported")
+	}
+	return in%2 == 0, nil
+}
+
+// captureTB records test errors rather than failing the test.
+type captureTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *captureTB) Error(args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprint(args...))
+}
+
+func TestRecordReplay_UnencodableResult(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workflow.json")
+	reg := registry.New()
+	ratio := sequin.RegisterIn(reg, func(_ context.Context, a, b float64) (float64, error) {
+		return a / b, nil
+	}, sequin.Name("replay.test.ratio"))
+
+	t.Run("record", func(t *testing.T) {
+		rt := NewRecorder(t, path, nil)
+		_, err := ratio(sequin.WithRuntime(context.Background(), rt), 0, 0)
+		require.ErrorContains(t, err, "result 0")
+		calls := rt.Calls()
+		require.Len(t, calls, 1)
+		require.Equal(t, err.Error(), calls[0].Error)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		rt := NewReplayer(t, path)
+		_, err := ratio(sequin.WithRuntime(context.Background(), rt), 0, 0)
+		require.ErrorContains(t, err, "result 0")
+	})
+}
+
+func TestEqualJSON(t *testing.T) {
+	raw := func(s ...string) []json.RawMessage {
+		var out []json.RawMessage
+		for _, v := range s {
+			out = append(out, json.RawMessage(v))
+		}
+		return out
+	}
+	require.True(t, equalJSON(raw(`{"a": 1, "b": [2]}`), raw(`{"b":[2],"a":1}`)))
+	require.False(t, equalJSON(raw(`9007199254740993`), raw(`9007199254740992`)))
+	require.False(t, equalJSON(raw(`1`), raw(`1`, `2`)))
+}
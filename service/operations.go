@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/vgough/sequin/local"
+)
+
+// OperationsServiceName is the fully-qualified name of the standard
+// long-running operations service.
+const OperationsServiceName = "google.longrunning.Operations"
+
+// Procedures of the long-running operations service.
+const (
+	OperationsListOperationsProcedure  = "/google.longrunning.Operations/ListOperations"
+	OperationsGetOperationProcedure    = "/google.longrunning.Operations/GetOperation"
+	OperationsDeleteOperationProcedure = "/google.longrunning.Operations/DeleteOperation"
+	OperationsCancelOperationProcedure = "/google.longrunning.Operations/CancelOperation"
+	OperationsWaitOperationProcedure   = "/google.longrunning.Operations/WaitOperation"
+)
+
+// NewOperationsHandler returns the path and handler of the standard
+// google.longrunning.Operations service, which manages the operations of the
+// Service using their request IDs as operation names. This allows existing
+// long-running operation clients to manage sequin operations.
+//
+// ListOperations accepts filters of the form
+//
+//	endpoint = "name" AND status = FAILED AND labels.team = "infra"
+//	AND submitted_at >= "2024-01-02T00:00:00Z" AND top_level = true
+//
+// where every term is optional. Status is one of RUNNING, SUCCEEDED or
+// FAILED, and submitted_at may be compared with any of =, <, <=, > and >=.
+func NewOperationsHandler(s *Service, opts ...connect.HandlerOption) (string, http.Handler) {
+	o := &operations{s: s}
+	methods := longrunningpb.File_google_longrunning_operations_proto.Services().ByName("Operations").Methods()
+	schema := func(name protoreflect.Name) connect.HandlerOption {
+		return connect.WithHandlerOptions(append(slices.Clip(opts), connect.WithSchema(methods.ByName(name)))...)
+	}
+	list := connect.NewUnaryHandler(OperationsListOperationsProcedure, o.ListOperations, schema("ListOperations"))
+	get := connect.NewUnaryHandler(OperationsGetOperationProcedure, o.GetOperation, schema("GetOperation"))
+	del := connect.NewUnaryHandler(OperationsDeleteOperationProcedure, o.DeleteOperation, schema("DeleteOperation"))
+	cancel := connect.NewUnaryHandler(OperationsCancelOperationProcedure, o.CancelOperation, schema("CancelOperation"))
+	wait := connect.NewUnaryHandler(OperationsWaitOperationProcedure, o.WaitOperation, schema("WaitOperation"))
+	return "/" + OperationsServiceName + "/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case OperationsListOperationsProcedure:
+			list.ServeHTTP(w, r)
+		case OperationsGetOperationProcedure:
+			get.ServeHTTP(w, r)
+		case OperationsDeleteOperationProcedure:
+			del.ServeHTTP(w, r)
+		case OperationsCancelOperationProcedure:
+			cancel.ServeHTTP(w, r)
+		case OperationsWaitOperationProcedure:
+			wait.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// operations implements google.longrunning.Operations.
+type operations struct {
+	s *Service
+}
+
+func (o *operations) ListOperations(ctx context.Context, req *connect.Request[longrunningpb.ListOperationsRequest]) (
+	*connect.Response[longrunningpb.ListOperationsResponse], error) {
+	q, err := parseFilter(req.Msg.GetFilter())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	if size := req.Msg.GetPageSize(); size < 0 || size > maxPageSize {
+		return nil, connect.NewError(connect.CodeInvalidArgument,
+			fmt.Errorf("page size must be between 0 and %d", maxPageSize))
+	}
+	q.PageSize = int(req.Msg.GetPageSize())
+	q.PageToken = req.Msg.GetPageToken()
+
+	ops, next, err := o.s.list(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&longrunningpb.ListOperationsResponse{
+		Operations:    ops,
+		NextPageToken: next,
+	}), nil
+}
+
+func (o *operations) GetOperation(ctx context.Context, req *connect.Request[longrunningpb.GetOperationRequest]) (
+	*connect.Response[longrunningpb.Operation], error) {
+	st, err := o.s.state(ctx, req.Msg.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return operationResponse(st)
+}
+
+func (o *operations) DeleteOperation(ctx context.Context, req *connect.Request[longrunningpb.DeleteOperationRequest]) (
+	*connect.Response[emptypb.Empty], error) {
+	id := req.Msg.GetName()
+	if _, err := o.s.state(ctx, id); err != nil {
+		return nil, err
+	}
+	err := o.s.server.Delete(ctx, id)
+	switch {
+	case errors.Is(err, local.ErrNotDone):
+		return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+	case errors.Is(err, local.ErrDeleteUnsupported):
+		return nil, connect.NewError(connect.CodeUnimplemented, err)
+	case err != nil:
+		return nil, err
+	}
+	return connect.NewResponse(&emptypb.Empty{}), nil
+}
+
+func (o *operations) CancelOperation(ctx context.Context, req *connect.Request[longrunningpb.CancelOperationRequest]) (
+	*connect.Response[emptypb.Empty], error) {
+	if err := o.s.cancel(ctx, req.Msg.GetName()); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&emptypb.Empty{}), nil
+}
+
+// WaitOperation waits until the operation is done or the timeout passes,
+// returning its latest state.
+func (o *operations) WaitOperation(ctx context.Context, req *connect.Request[longrunningpb.WaitOperationRequest]) (
+	*connect.Response[longrunningpb.Operation], error) {
+	id := req.Msg.GetName()
+	waitCtx := ctx
+	if req.Msg.Timeout != nil {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, req.Msg.GetTimeout().AsDuration())
+		defer cancel()
+	}
+	st, err := o.s.state(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !st.done {
+		st, err = o.s.wait(waitCtx, id)
+		if err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			st, err = o.s.state(ctx, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return operationResponse(st)
+}
+
+func operationResponse(st *opState) (*connect.Response[longrunningpb.Operation], error) {
+	op, err := st.operation()
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(op), nil
+}
+
+// filterTerm matches a term of a ListOperations filter.
+var filterTerm = regexp.MustCompile(`^\s*([A-Za-z_][\w.-]*)\s*(>=|<=|=|<|>)\s*("(?:[^"\\]|\\.)*"|[^\s"]+)`)
+
+// filterAnd matches the conjunction between terms of a filter.
+var filterAnd = regexp.MustCompile(`^\s+AND\s+`)
+
+// parseFilter parses a ListOperations filter into a query.
+func parseFilter(filter string) (local.Query, error) {
+	var q local.Query
+	rest := strings.TrimSpace(filter)
+	for rest != "" {
+		m := filterTerm.FindStringSubmatch(rest)
+		if m == nil {
+			return q, fmt.Errorf("invalid filter at %q", rest)
+		}
+		rest = rest[len(m[0]):]
+		if rest != "" {
+			sep := filterAnd.FindString(rest)
+			if sep == "" {
+				return q, fmt.Errorf("invalid filter at %q, expected AND", strings.TrimSpace(rest))
+			}
+			rest = rest[len(sep):]
+		}
+		if err := applyFilterTerm(&q, m[1], m[2], m[3]); err != nil {
+			return q, err
+		}
+	}
+	return q, nil
+}
+
+func applyFilterTerm(q *local.Query, key, op, value string) error {
+	if strings.HasPrefix(value, `"`) {
+		var err error
+		if value, err = strconv.Unquote(value); err != nil {
+			return fmt.Errorf("invalid string %s in filter: %v", value, err)
+		}
+	}
+	if key == "submitted_at" {
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return fmt.Errorf("invalid time %q in filter: %v", value, err)
+		}
+		// Bounds are stored as an inclusive lower bound and an exclusive
+		// upper bound.
+		switch op {
+		case "=":
+			q.SubmittedAfter, q.SubmittedBefore = t, t.Add(time.Nanosecond)
+		case ">=":
+			q.SubmittedAfter = t
+		case ">":
+			q.SubmittedAfter = t.Add(time.Nanosecond)
+		case "<":
+			q.SubmittedBefore = t
+		case "<=":
+			q.SubmittedBefore = t.Add(time.Nanosecond)
+		}
+		return nil
+	}
+	if op != "=" {
+		return fmt.Errorf("%s can only be compared with =", key)
+	}
+
+	switch {
+	case key == "endpoint":
+		q.Name = value
+	case key == "status":
+		switch strings.ToUpper(value) {
+		case "RUNNING":
+			q.Status = local.StatusRunning
+		case "SUCCEEDED":
+			q.Status = local.StatusSucceeded
+		case "FAILED":
+			q.Status = local.StatusFailed
+		default:
+			return fmt.Errorf("unknown status %q in filter", value)
+		}
+	case key == "top_level":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid top_level %q in filter", value)
+		}
+		q.TopLevel = v
+	case strings.HasPrefix(key, "labels.") && len(key) > len("labels."):
+		if q.Labels == nil {
+			q.Labels = make(map[string]string)
+		}
+		q.Labels[strings.TrimPrefix(key, "labels.")] = value
+	default:
+		return fmt.Errorf("unknown field %q in filter", key)
+	}
+	return nil
+}
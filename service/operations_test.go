@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	sequinv1 "github.com/vgough/sequin/gen/sequin/v1"
+	"github.com/vgough/sequin/internal"
+	"github.com/vgough/sequin/local"
+	"github.com/vgough/sequin/registry"
+)
+
+// TestOperations uses the standard gRPC client of the long-running operations
+// service.
+func TestOperations(t *testing.T) {
+	env := newTestEnv(t)
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	ctx, stop := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		errc <- Serve(ctx, ln, WithServer(env.server), WithShutdownTimeout(5*time.Second),
+			WithServiceOptions(WithPollInterval(10*time.Millisecond)))
+	}()
+	t.Cleanup(func() {
+		stop()
+		require.NoError(t, <-errc)
+	})
+
+	conn, err := grpc.NewClient(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := longrunningpb.NewOperationsClient(conn)
+
+	ok := env.start(t, jsonOp(t, "service.test.sum-squares", []any{1, 2}))
+	failed := env.start(t, jsonOp(t, "service.test.sum-squares", []any{3, -1}))
+	blocked := env.start(t, jsonOp(t, "service.test.block", "d"))
+
+	op, err := client.WaitOperation(ctx, &longrunningpb.WaitOperationRequest{Name: ok})
+	require.NoError(t, err)
+	require.True(t, op.GetDone())
+	op, err = client.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: ok})
+	require.NoError(t, err)
+	require.True(t, op.GetDone())
+	var resp sequinv1.ExecResponse
+	require.NoError(t, op.GetResponse().UnmarshalTo(&resp))
+	var v structpb.Value
+	require.NoError(t, resp.GetResults()[0].UnmarshalTo(&v))
+	require.EqualValues(t, 5, v.GetNumberValue())
+
+	op, err = client.WaitOperation(ctx, &longrunningpb.WaitOperationRequest{Name: failed})
+	require.NoError(t, err)
+	require.Equal(t, "negative input", op.GetError().GetMessage())
+
+	// Waiting returns the latest state once the timeout passes.
+	op, err = client.WaitOperation(ctx, &longrunningpb.WaitOperationRequest{
+		Name:    blocked,
+		Timeout: durationpb.New(20 * time.Millisecond),
+	})
+	require.NoError(t, err)
+	require.False(t, op.GetDone())
+
+	list, err := client.ListOperations(ctx, &longrunningpb.ListOperationsRequest{
+		Filter: `endpoint = "service.test.sum-squares" AND status = FAILED AND top_level = true`,
+	})
+	require.NoError(t, err)
+	require.Len(t, list.GetOperations(), 1)
+	require.Equal(t, failed, list.GetOperations()[0].GetName())
+
+	list, err = client.ListOperations(ctx, &longrunningpb.ListOperationsRequest{PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, list.GetOperations(), 2)
+	require.NotEmpty(t, list.GetNextPageToken())
+
+	// Running operations can't be deleted.
+	_, err = client.DeleteOperation(ctx, &longrunningpb.DeleteOperationRequest{Name: blocked})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	require.Eventually(t, func() bool {
+		_, err := client.CancelOperation(ctx, &longrunningpb.CancelOperationRequest{Name: blocked})
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	op, err = client.WaitOperation(ctx, &longrunningpb.WaitOperationRequest{Name: blocked})
+	require.NoError(t, err)
+	require.Equal(t, int32(codes.Canceled), op.GetError().GetCode())
+
+	_, err = client.DeleteOperation(ctx, &longrunningpb.DeleteOperationRequest{Name: failed})
+	require.NoError(t, err)
+	_, err = client.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: failed})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.ListOperations(ctx, &longrunningpb.ListOperationsRequest{Filter: "status = DONE"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestParseFilter(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		filter string
+		want   local.Query
+		err    string
+	}{
+		{filter: "", want: local.Query{}},
+		{
+			filter: `endpoint = "a.b" AND status=failed AND labels.team = infra AND top_level = true`,
+			want: local.Query{Name: "a.b", Status: local.StatusFailed,
+				Labels: map[string]string{"team": "infra"}, TopLevel: true},
+		},
+		{
+			filter: `submitted_at >= "2024-01-02T00:00:00Z" AND submitted_at < 2024-01-02T00:00:00Z`,
+			want:   local.Query{SubmittedAfter: ts, SubmittedBefore: ts},
+		},
+		{filter: `labels.x = "a \"quoted\" value"`, want: local.Query{Labels: map[string]string{"x": `a "quoted" value`}}},
+		{filter: `status = DONE`, err: `unknown status "DONE"`},
+		{filter: `endpoint > a`, err: "can only be compared with ="},
+		{filter: `owner = a`, err: `unknown field "owner"`},
+		{filter: `endpoint = a OR status = FAILED`, err: "expected AND"},
+		{filter: `endpoint = a AND`, err: "expected AND"},
+		{filter: `submitted_at > yesterday`, err: "invalid time"},
+		{filter: `= a`, err: "invalid filter"},
+	}
+	for _, tc := range tests {
+		q, err := parseFilter(tc.filter)
+		if tc.err != "" {
+			require.ErrorContains(t, err, tc.err, tc.filter)
+			continue
+		}
+		require.NoError(t, err, tc.filter)
+		require.Equal(t, tc.want, q, tc.filter)
+	}
+}
+
+func TestOperations_UnregisteredEndpoint(t *testing.T) {
+	ctx := context.Background()
+	encodeErr := func(err error) []byte {
+		data, encErr := internal.EncodeValue(reflect.ValueOf(&err).Elem())
+		require.NoError(t, encErr)
+		return data
+	}
+	store := local.NewMemoryStore()
+	for _, req := range []*local.Request{
+		{ID: "stale-ok", Name: "service.test.removed", Done: true, Results: [][]byte{{1}, nil}},
+		{ID: "stale-failed", Name: "service.test.removed", Done: true, Failed: true,
+			Results: [][]byte{nil, encodeErr(context.Canceled)}},
+	} {
+		require.NoError(t, store.Put(ctx, req))
+	}
+	ops := &operations{s: New(local.NewServer(local.WithRegistry(registry.New()), local.WithStore(store)))}
+
+	list, err := ops.ListOperations(ctx, connect.NewRequest(&longrunningpb.ListOperationsRequest{}))
+	require.NoError(t, err)
+	require.Len(t, list.Msg.GetOperations(), 2)
+
+	op, err := ops.GetOperation(ctx, connect.NewRequest(&longrunningpb.GetOperationRequest{Name: "stale-ok"}))
+	require.NoError(t, err)
+	require.True(t, op.Msg.GetDone())
+	require.NotNil(t, op.Msg.GetResponse())
+	var md sequinv1.RunMetadata
+	require.NoError(t, op.Msg.GetMetadata().UnmarshalTo(&md))
+	require.Equal(t, "service.test.removed", md.GetEndpoint())
+
+	op, err = ops.GetOperation(ctx, connect.NewRequest(&longrunningpb.GetOperationRequest{Name: "stale-failed"}))
+	require.NoError(t, err)
+	require.Equal(t, int32(codes.Canceled), op.Msg.GetError().GetCode())
+
+	_, err = ops.DeleteOperation(ctx, connect.NewRequest(&longrunningpb.DeleteOperationRequest{Name: "stale-failed"}))
+	require.NoError(t, err)
+	req, err := store.Get(ctx, "stale-failed")
+	require.NoError(t, err)
+	require.Nil(t, req)
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"connectrpc.com/connect"
+	"connectrpc.com/grpcreflect"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/vgough/sequin/gen/sequin/v1/sequinv1connect"
+	"github.com/vgough/sequin/local"
+)
+
+// HealthPath is the path of the HTTP health check served by Handler.
+const HealthPath = "/healthz"
+
+// MetricsPath is the path at which Serve exposes metrics, if a metrics handler
+// is set.
+const MetricsPath = "/metrics"
+
+// healthCheckProcedure is the gRPC health checking protocol's Check method.
+const healthCheckProcedure = "/grpc.health.v1.Health/Check"
+
+// Handler returns an http.Handler serving SequinService, along with:
+//
+//   - the standard google.longrunning.Operations service, see
+//     NewOperationsHandler.
+//   - an HTTP health check at HealthPath, which fails once draining.
+//   - the Check method of the gRPC health checking protocol.
+//   - gRPC server reflection.
+func (s *Service) Handler(opts ...connect.HandlerOption) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(sequinv1connect.NewSequinServiceHandler(s, opts...))
+	mux.Handle(NewOperationsHandler(s, opts...))
+	mux.Handle(healthCheckProcedure, connect.NewUnaryHandler(healthCheckProcedure, s.healthCheck, opts...))
+	mux.HandleFunc(HealthPath, func(w http.ResponseWriter, r *http.Request) {
+		if !s.Serving() {
+			http.Error(w, "draining", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok\n"))
+	})
+
+	reflector := grpcreflect.NewStaticReflector(sequinv1connect.SequinServiceName, OperationsServiceName)
+	mux.Handle(grpcreflect.NewHandlerV1(reflector, opts...))
+	mux.Handle(grpcreflect.NewHandlerV1Alpha(reflector, opts...))
+	return mux
+}
+
+func (s *Service) healthCheck(ctx context.Context, req *connect.Request[grpc_health_v1.HealthCheckRequest]) (
+	*connect.Response[grpc_health_v1.HealthCheckResponse], error) {
+	switch req.Msg.GetService() {
+	case "", sequinv1connect.SequinServiceName:
+	default:
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("unknown service"))
+	}
+	st := grpc_health_v1.HealthCheckResponse_SERVING
+	if !s.Serving() {
+		st = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	return connect.NewResponse(&grpc_health_v1.HealthCheckResponse{Status: st}), nil
+}
+
+type serveConfig struct {
+	server          *local.Server
+	opts            []Option
+	handlerOpts     []connect.HandlerOption
+	metrics         http.Handler
+	logger          *slog.Logger
+	shutdownTimeout time.Duration
+}
+
+// ServeOption configures Serve and ListenAndServe.
+type ServeOption func(*serveConfig)
+
+// WithServer sets the server used to execute operations.
+// The default is a new local.Server using the default registry.
+func WithServer(srv *local.Server) ServeOption {
+	return func(c *serveConfig) {
+		c.server = srv
+	}
+}
+
+// WithServiceOptions sets options for the Service.
+func WithServiceOptions(opts ...Option) ServeOption {
+	return func(c *serveConfig) {
+		c.opts = append(c.opts, opts...)
+	}
+}
+
+// WithHandlerOptions sets connect options for the handlers, such as
+// interceptors.
+func WithHandlerOptions(opts ...connect.HandlerOption) ServeOption {
+	return func(c *serveConfig) {
+		c.handlerOpts = append(c.handlerOpts, opts...)
+	}
+}
+
+// WithMetricsHandler sets the handler serving metrics at MetricsPath, such as
+// a metrics.Prometheus which is also given to the server by local.WithMetrics.
+func WithMetricsHandler(h http.Handler) ServeOption {
+	return func(c *serveConfig) {
+		c.metrics = h
+	}
+}
+
+// WithShutdownTimeout sets how long to wait for in-flight requests and started
+// operations when shutting down. The default is 30 seconds.
+func WithShutdownTimeout(d time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.shutdownTimeout = d
+	}
+}
+
+// WithLogger sets the logger used to report when serving starts and stops.
+// The default is slog.Default.
+func WithLogger(l *slog.Logger) ServeOption {
+	return func(c *serveConfig) {
+		c.logger = l
+	}
+}
+
+// ListenAndServe listens on the TCP address and calls Serve.
+func ListenAndServe(ctx context.Context, addr string, opts ...ServeOption) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return Serve(ctx, ln, opts...)
+}
+
+// Serve hosts a Service over HTTP/2, with or without TLS, until the context
+// is done. See Service.Handler for what is served. Trace context is
+// propagated from requests using NewTraceInterceptor.
+//
+// The server is started if it is idle. On shutdown, the service stops
+// accepting new operations and reports itself unhealthy, then waits for
+// in-flight requests and the operations it started to complete, up to the
+// shutdown timeout. The server is then shut down, which checkpoints operations
+// which didn't complete in time, so they resume when next started. See
+// local.Server.Shutdown.
+//
+// This allows a worker binary to be a few lines of main():
+//
+//	func main() {
+//		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+//		defer stop()
+//		if err := service.ListenAndServe(ctx, ":8080"); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func Serve(ctx context.Context, ln net.Listener, opts ...ServeOption) error {
+	c := &serveConfig{shutdownTimeout: 30 * time.Second}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.server == nil {
+		c.server = local.NewServer()
+	}
+	if c.logger == nil {
+		c.logger = slog.Default()
+	}
+
+	if c.server.Health() == local.Idle {
+		if err := c.server.Start(); err != nil {
+			return err
+		}
+	}
+
+	svc := New(c.server, c.opts...)
+	handlerOpts := append([]connect.HandlerOption{
+		connect.WithInterceptors(NewTraceInterceptor(nil)),
+	}, c.handlerOpts...)
+	handler := svc.Handler(handlerOpts...)
+	if c.metrics != nil {
+		mux := http.NewServeMux()
+		mux.Handle("/", handler)
+		mux.Handle(MetricsPath, c.metrics)
+		handler = mux
+	}
+	srv := &http.Server{
+		Handler:           h2c.NewHandler(handler, &http2.Server{}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Serve(ln)
+	}()
+	c.logger.Info("sequin: serving", "addr", ln.Addr().String())
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	c.logger.Info("sequin: shutting down")
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.shutdownTimeout)
+	defer cancel()
+	drainErr := svc.Drain(ctx)
+	if err := c.server.Shutdown(ctx); err != nil && drainErr == nil {
+		drainErr = err
+	}
+	if err := srv.Shutdown(ctx); err != nil {
+		srv.Close()
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return drainErr
+}
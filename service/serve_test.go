@@ -0,0 +1,102 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"connectrpc.com/grpcreflect"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	sequinv1 "github.com/vgough/sequin/gen/sequin/v1"
+	"github.com/vgough/sequin/gen/sequin/v1/sequinv1connect"
+)
+
+// h2cClient returns a client which uses HTTP/2 without TLS.
+func h2cClient() *http.Client {
+	return &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+}
+
+func TestServe(t *testing.T) {
+	env := newTestEnv(t)
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	url := "http://" + ln.Addr().String()
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	errc := make(chan error, 1)
+	go func() {
+		errc <- Serve(ctx, ln, WithServer(env.server), WithShutdownTimeout(5*time.Second), WithLogger(logger))
+	}()
+
+	// Use the gRPC protocol, which requires HTTP/2.
+	client := h2cClient()
+	svc := sequinv1connect.NewSequinServiceClient(client, url, connect.WithGRPC())
+	health := connect.NewClient[grpc_health_v1.HealthCheckRequest, grpc_health_v1.HealthCheckResponse](
+		client, url+healthCheckProcedure, connect.WithGRPC())
+	checkHealth := func() grpc_health_v1.HealthCheckResponse_ServingStatus {
+		resp, err := health.CallUnary(ctx, connect.NewRequest(&grpc_health_v1.HealthCheckRequest{}))
+		require.NoError(t, err)
+		return resp.Msg.GetStatus()
+	}
+	checkHTTP := func() int {
+		resp, err := client.Get(url + HealthPath)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.Eventually(t, func() bool {
+		_, err := svc.ListEndpoints(ctx, connect.NewRequest(&sequinv1.ListEndpointsRequest{}))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, checkHealth())
+	require.Equal(t, http.StatusOK, checkHTTP())
+
+	reflect := grpcreflect.NewClient(client, url)
+	stream := reflect.NewStream(ctx)
+	services, err := stream.ListServices()
+	require.NoError(t, err)
+	require.Contains(t, services, protoreflect.FullName(sequinv1connect.SequinServiceName))
+	stream.Close()
+
+	// Shutting down waits for started operations.
+	resp, err := svc.Start(ctx, connect.NewRequest(&sequinv1.StartRequest{Operation: jsonOp(t, "service.test.block", "c")}))
+	require.NoError(t, err)
+	stop()
+	ctx = context.Background()
+	require.Eventually(t, func() bool {
+		return checkHealth() == grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, http.StatusServiceUnavailable, checkHTTP())
+
+	_, err = svc.Start(ctx, connect.NewRequest(&sequinv1.StartRequest{Operation: jsonOp(t, "service.test.square", 2)}))
+	require.Equal(t, connect.CodeUnavailable, connect.CodeOf(err))
+
+	close(env.release)
+	require.NoError(t, <-errc)
+	require.Contains(t, logs.String(), "sequin: serving")
+	require.Contains(t, logs.String(), "sequin: shutting down")
+
+	req, err := env.server.Lookup(ctx, resp.Msg.GetRequestId())
+	require.NoError(t, err)
+	require.True(t, req.Done)
+}
@@ -0,0 +1,732 @@
+// Package service implements the SequinService API over a local.Server.
+//
+// Operations are started asynchronously, and are identified by the request
+// ID assigned by the server. Both FuncOperation and JSONOperation operations
+// are accepted. Results which are protobuf messages are returned as such, and
+// other results are converted to JSON and returned as google.protobuf.Value
+// messages.
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/vgough/sequin"
+	sequinv1 "github.com/vgough/sequin/gen/sequin/v1"
+	"github.com/vgough/sequin/gen/sequin/v1/sequinv1connect"
+	"github.com/vgough/sequin/internal"
+	"github.com/vgough/sequin/local"
+	"github.com/vgough/sequin/registry"
+)
+
+// ErrDraining is returned when starting operations on a draining service.
+var ErrDraining = errors.New("service: draining, not accepting new operations")
+
+// maxPageSize is the largest page size accepted by ListOperations.
+const maxPageSize = 1000
+
+// Update IDs reported by Get.
+const (
+	updateRunning = "running"
+	updateDone    = "done"
+)
+
+// Service implements SequinService by executing operations on a local.Server.
+type Service struct {
+	server       *local.Server
+	registry     *registry.Registry
+	pollInterval time.Duration
+
+	mu       sync.Mutex
+	started  map[string]*run // operations started by this service, by ID.
+	draining bool            // set once Drain is called.
+	running  sync.WaitGroup  // started operations which have not completed.
+}
+
+var _ sequinv1connect.SequinServiceHandler = &Service{}
+
+// run is an operation started by the service.
+type run struct {
+	done chan struct{} // closed once the operation completes.
+}
+
+// Option configures a Service.
+type Option func(*Service)
+
+// WithPollInterval sets how often the store is checked when waiting for
+// operations which were not started by this service. The default is one
+// second.
+func WithPollInterval(d time.Duration) Option {
+	return func(s *Service) {
+		s.pollInterval = d
+	}
+}
+
+// New returns a Service which executes operations on the server, resolving
+// endpoints using the server's registry.
+func New(srv *local.Server, opts ...Option) *Service {
+	s := &Service{
+		server:       srv,
+		registry:     srv.Registry(),
+		pollInterval: time.Second,
+		started:      make(map[string]*run),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Drain stops the service from starting new operations, and waits for the
+// operations it started to complete, or for the context to be done.
+func (s *Service) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	s.draining = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+		return nil
+	}
+}
+
+// Serving returns false once the service or its server starts draining.
+// Servers which have not been started are considered serving.
+func (s *Service) Serving() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.draining && s.server.Health() < local.Draining
+}
+
+// Start begins an operation, returning its request ID.
+func (s *Service) Start(ctx context.Context, req *connect.Request[sequinv1.StartRequest]) (
+	*connect.Response[sequinv1.StartResponse], error) {
+	if err := req.Msg.Validate(); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	id, err := s.start(ctx, req.Msg.GetOperation(), req.Msg.GetMetadata())
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&sequinv1.StartResponse{RequestId: id}), nil
+}
+
+// Get returns the current state of an operation. If LastUpdateId is set to
+// the current update ID, Get waits for the operation to complete.
+func (s *Service) Get(ctx context.Context, req *connect.Request[sequinv1.GetRequest]) (
+	*connect.Response[sequinv1.GetResponse], error) {
+	if err := req.Msg.Validate(); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	id := req.Msg.GetRequestId()
+	st, err := s.state(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !st.done && req.Msg.GetLastUpdateId() == updateRunning {
+		if st, err = s.wait(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &sequinv1.GetResponse{
+		Results:      st.results,
+		Metadata:     st.metadata(),
+		LastUpdateId: updateRunning,
+	}
+	if st.done {
+		resp.LastUpdateId = updateDone
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// Exec starts an operation and streams its state until it completes.
+func (s *Service) Exec(ctx context.Context, req *connect.Request[sequinv1.ExecRequest],
+	stream *connect.ServerStream[longrunningpb.Operation]) error {
+	if err := req.Msg.Validate(); err != nil {
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	id, err := s.start(ctx, req.Msg.GetOperation(), req.Msg.GetMetadata())
+	if err != nil {
+		return err
+	}
+	return s.watch(ctx, id, stream)
+}
+
+// Watch streams the state of an operation until it completes.
+func (s *Service) Watch(ctx context.Context, req *connect.Request[sequinv1.WatchRequest],
+	stream *connect.ServerStream[longrunningpb.Operation]) error {
+	if err := req.Msg.Validate(); err != nil {
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	if err := requireID(req.Msg.GetRequestId()); err != nil {
+		return err
+	}
+	return s.watch(ctx, req.Msg.GetRequestId(), stream)
+}
+
+// Cancel cancels an operation which is executing on the server.
+func (s *Service) Cancel(ctx context.Context, req *connect.Request[sequinv1.CancelRequest]) (
+	*connect.Response[sequinv1.CancelResponse], error) {
+	if err := req.Msg.Validate(); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	if err := requireID(req.Msg.GetRequestId()); err != nil {
+		return nil, err
+	}
+	if err := s.cancel(ctx, req.Msg.GetRequestId()); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&sequinv1.CancelResponse{}), nil
+}
+
+// requireID returns an InvalidArgument error if the request ID is empty. The
+// generated Validate methods don't check buf.validate rules, so the min_len
+// rule of request IDs is checked here.
+func requireID(id string) error {
+	if id == "" {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("request_id is required"))
+	}
+	return nil
+}
+
+func (s *Service) cancel(ctx context.Context, id string) error {
+	if err := s.server.Cancel(id); errors.Is(err, local.ErrNotRunning) {
+		st, err := s.state(ctx, id)
+		if err != nil {
+			return err
+		}
+		if st.done {
+			return connect.NewError(connect.CodeFailedPrecondition,
+				fmt.Errorf("operation %s is already done", id))
+		}
+		return connect.NewError(connect.CodeUnavailable,
+			fmt.Errorf("operation %s is not executing on this server", id))
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListEndpoints describes the endpoints in the server's registry.
+func (s *Service) ListEndpoints(ctx context.Context, req *connect.Request[sequinv1.ListEndpointsRequest]) (
+	*connect.Response[sequinv1.ListEndpointsResponse], error) {
+	resp := &sequinv1.ListEndpointsResponse{}
+	for _, info := range s.registry.Describe() {
+		ep := &sequinv1.Endpoint{
+			Name:    info.Name,
+			Version: int32(info.Version),
+			Aliases: info.Aliases,
+		}
+		for _, arg := range info.Args {
+			if arg.Name != "" {
+				ep.ArgNames = append(ep.ArgNames, arg.Name)
+			}
+		}
+		var err error
+		if ep.ArgsSchema, err = toStruct(info.ArgsSchema); err != nil {
+			return nil, err
+		}
+		if ep.ResultsSchema, err = toStruct(info.ResultsSchema); err != nil {
+			return nil, err
+		}
+		resp.Endpoints = append(resp.Endpoints, ep)
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// GetCallTree returns the state of an operation and the calls it made.
+func (s *Service) GetCallTree(ctx context.Context, req *connect.Request[sequinv1.GetCallTreeRequest]) (
+	*connect.Response[sequinv1.GetCallTreeResponse], error) {
+	if err := req.Msg.Validate(); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	if err := requireID(req.Msg.GetRequestId()); err != nil {
+		return nil, err
+	}
+	root, err := s.callTree(ctx, req.Msg.GetRequestId(), "")
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&sequinv1.GetCallTreeResponse{Root: root}), nil
+}
+
+// ListOperations returns the operations stored by the server which match the
+// filters of the request.
+func (s *Service) ListOperations(ctx context.Context, req *connect.Request[sequinv1.ListOperationsRequest]) (
+	*connect.Response[sequinv1.ListOperationsResponse], error) {
+	if err := req.Msg.Validate(); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	q, err := listQuery(req.Msg)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	ops, next, err := s.list(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&sequinv1.ListOperationsResponse{Operations: ops, NextPageToken: next}), nil
+}
+
+// list returns the operations selected by the query, and the token of the
+// next page.
+func (s *Service) list(ctx context.Context, q local.Query) ([]*longrunningpb.Operation, string, error) {
+	reqs, next, err := s.server.List(ctx, q)
+	switch {
+	case errors.Is(err, local.ErrListUnsupported):
+		return nil, "", connect.NewError(connect.CodeUnimplemented, err)
+	case errors.Is(err, local.ErrInvalidPageToken):
+		return nil, "", connect.NewError(connect.CodeInvalidArgument, err)
+	case err != nil:
+		return nil, "", err
+	}
+
+	ops := make([]*longrunningpb.Operation, 0, len(reqs))
+	for _, r := range reqs {
+		st, err := s.decode(r)
+		if err != nil {
+			return nil, "", err
+		}
+		op, err := st.operation()
+		if err != nil {
+			return nil, "", err
+		}
+		ops = append(ops, op)
+	}
+	return ops, next, nil
+}
+
+// listQuery converts the filters of a ListOperationsRequest to a query.
+func listQuery(msg *sequinv1.ListOperationsRequest) (local.Query, error) {
+	q := local.Query{
+		Name:      msg.GetName(),
+		Labels:    msg.GetLabels(),
+		TopLevel:  msg.GetTopLevel(),
+		PageSize:  int(msg.GetPageSize()),
+		PageToken: msg.GetPageToken(),
+	}
+	if q.PageSize < 0 || q.PageSize > maxPageSize {
+		return q, fmt.Errorf("page size must be between 0 and %d", maxPageSize)
+	}
+	switch msg.GetStatus() {
+	case sequinv1.OperationStatus_OPERATION_STATUS_UNSPECIFIED:
+	case sequinv1.OperationStatus_OPERATION_STATUS_RUNNING:
+		q.Status = local.StatusRunning
+	case sequinv1.OperationStatus_OPERATION_STATUS_SUCCEEDED:
+		q.Status = local.StatusSucceeded
+	case sequinv1.OperationStatus_OPERATION_STATUS_FAILED:
+		q.Status = local.StatusFailed
+	default:
+		return q, fmt.Errorf("unknown status %v", msg.GetStatus())
+	}
+	if msg.SubmittedAfter != nil {
+		q.SubmittedAfter = msg.GetSubmittedAfter().AsTime()
+	}
+	if msg.SubmittedBefore != nil {
+		q.SubmittedBefore = msg.GetSubmittedBefore().AsTime()
+	}
+	return q, nil
+}
+
+func (s *Service) callTree(ctx context.Context, id, name string) (*sequinv1.CallNode, error) {
+	node := &sequinv1.CallNode{RequestId: id, Name: name}
+	req, err := s.server.Lookup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		if name == "" {
+			return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("operation %s not found", id))
+		}
+		// The call has been issued, but not yet stored.
+		return node, nil
+	}
+
+	node.Name = req.Name
+	st, err := s.decode(req)
+	if err != nil {
+		return nil, err
+	}
+	node.Done = st.done
+	node.Status = st.status
+	for _, c := range req.Children {
+		child, err := s.callTree(ctx, c.ID, c.Name)
+		if err != nil {
+			return nil, err
+		}
+		child.Detached = c.Detached
+		node.Children = append(node.Children, child)
+	}
+	return node, nil
+}
+
+// start begins executing an operation in the background, returning its ID.
+// Operations which are already executing are not started again.
+// The execution is traced as part of the trace of the given context, and
+// labelled with the labels of the request metadata.
+func (s *Service) start(ctx context.Context, op *anypb.Any, md *sequinv1.RequestMetadata) (string, error) {
+	ep, args, err := s.prepare(op)
+	if err != nil {
+		return "", err
+	}
+	ctx = sequin.WithRuntime(detach(ctx), s.server)
+	ctx = sequin.WithLabels(ctx, md.GetLabels())
+	ep.SetContext(ctx, args)
+	id, err := s.server.RequestID(ep, args)
+	if err != nil {
+		return "", connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.draining {
+		return "", connect.NewError(connect.CodeUnavailable, ErrDraining)
+	}
+	if _, ok := s.started[id]; ok {
+		return id, nil
+	}
+	r := &run{done: make(chan struct{})}
+	s.started[id] = r
+	s.running.Add(1)
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.started, id)
+			s.mu.Unlock()
+			close(r.done)
+			s.running.Done()
+		}()
+		// The outcome is stored by the server.
+		s.server.Exec(ep, args)
+	}()
+	return id, nil
+}
+
+// prepare resolves the endpoint and arguments of an operation.
+func (s *Service) prepare(op *anypb.Any) (*registry.Endpoint, []reflect.Value, error) {
+	msg, err := op.UnmarshalNew()
+	if err != nil {
+		return nil, nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	var (
+		ep   *registry.Endpoint
+		args []reflect.Value
+	)
+	switch msg := msg.(type) {
+	case *sequinv1.FuncOperation:
+		if err := msg.Validate(); err != nil {
+			return nil, nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		if ep, err = s.endpoint(msg.GetName()); err != nil {
+			return nil, nil, err
+		}
+		args, err = ep.UnpackArgs(msg.GetArgs())
+	case *sequinv1.JSONOperation:
+		if err := msg.Validate(); err != nil {
+			return nil, nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		if ep, err = s.endpoint(msg.GetName()); err != nil {
+			return nil, nil, err
+		}
+		var data []byte
+		if msg.GetArgs() != nil {
+			if data, err = protojson.Marshal(msg.GetArgs()); err != nil {
+				return nil, nil, connect.NewError(connect.CodeInvalidArgument, err)
+			}
+		}
+		args, err = ep.UnmarshalArgs(data)
+	default:
+		return nil, nil, connect.NewError(connect.CodeInvalidArgument,
+			fmt.Errorf("unsupported operation type %s", op.GetTypeUrl()))
+	}
+	if err != nil {
+		return nil, nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	return ep, args, nil
+}
+
+func (s *Service) endpoint(name string) (*registry.Endpoint, error) {
+	ep := s.registry.GetEndpoint(name)
+	if ep == nil {
+		return nil, connect.NewError(connect.CodeNotFound,
+			fmt.Errorf("%w: %s", registry.ErrEndpointNotFound, name))
+	}
+	return ep, nil
+}
+
+// watch streams the state of an operation until it completes.
+func (s *Service) watch(ctx context.Context, id string, stream *connect.ServerStream[longrunningpb.Operation]) error {
+	st, err := s.state(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.send(stream, st); err != nil || st.done {
+		return err
+	}
+	if st, err = s.wait(ctx, id); err != nil {
+		return err
+	}
+	return s.send(stream, st)
+}
+
+func (s *Service) send(stream *connect.ServerStream[longrunningpb.Operation], st *opState) error {
+	op, err := st.operation()
+	if err != nil {
+		return err
+	}
+	return stream.Send(op)
+}
+
+// wait waits for an operation to complete, returning its final state.
+func (s *Service) wait(ctx context.Context, id string) (*opState, error) {
+	s.mu.Lock()
+	r := s.started[id]
+	s.mu.Unlock()
+	if r != nil {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-r.done:
+		}
+		return s.state(ctx, id)
+	}
+
+	// The operation was started elsewhere, so check the store until it is done.
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		st, err := s.state(ctx, id)
+		if err != nil || st.done {
+			return st, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// opState is the state of an operation.
+type opState struct {
+	id         string
+	done       bool
+	results    []*anypb.Any
+	labels     map[string]string
+	status     *status.Status // set once done.
+	endpoint   string
+	originator string
+
+	compensations []*sequinv1.Compensation
+
+	submittedAt, startedAt, finishedAt time.Time
+}
+
+func (st *opState) metadata() *sequinv1.RunMetadata {
+	return &sequinv1.RunMetadata{
+		Endpoint:      st.endpoint,
+		Originator:    st.originator,
+		Compensations: st.compensations,
+		Labels:        st.labels,
+		SubmittedAt:   timestamp(st.submittedAt),
+		StartedAt:     timestamp(st.startedAt),
+		FinishedAt:    timestamp(st.finishedAt),
+		Status:        st.status,
+	}
+}
+
+// timestamp converts a time to a Timestamp, or nil if it is zero.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func (st *opState) operation() (*longrunningpb.Operation, error) {
+	md, err := anypb.New(st.metadata())
+	if err != nil {
+		return nil, err
+	}
+	op := &longrunningpb.Operation{Name: st.id, Done: st.done, Metadata: md}
+	switch {
+	case !st.done:
+	case st.status.GetCode() != int32(code.Code_OK):
+		op.Result = &longrunningpb.Operation_Error{Error: st.status}
+	default:
+		resp, err := anypb.New(&sequinv1.ExecResponse{Results: st.results, Metadata: st.metadata()})
+		if err != nil {
+			return nil, err
+		}
+		op.Result = &longrunningpb.Operation_Response{Response: resp}
+	}
+	return op, nil
+}
+
+// state returns the current state of an operation.
+func (s *Service) state(ctx context.Context, id string) (*opState, error) {
+	req, err := s.server.Lookup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		s.mu.Lock()
+		_, ok := s.started[id]
+		s.mu.Unlock()
+		if ok {
+			// Started, but not yet stored by the server.
+			return &opState{id: id}, nil
+		}
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("operation %s not found", id))
+	}
+	return s.decode(req)
+}
+
+// decode returns the state of a stored request.
+func (s *Service) decode(req *local.Request) (*opState, error) {
+	st := &opState{
+		id:          req.ID,
+		endpoint:    req.Name,
+		originator:  req.Originator,
+		done:        req.Done,
+		labels:      req.Labels,
+		submittedAt: req.SubmittedAt,
+		startedAt:   req.StartedAt,
+		finishedAt:  req.FinishedAt,
+	}
+	for _, c := range req.Compensations {
+		comp := &sequinv1.Compensation{
+			Step:      c.StepID,
+			Endpoint:  c.Name,
+			RequestId: c.RequestID,
+			Done:      c.Done,
+		}
+		switch {
+		case c.Error != "":
+			comp.Status = errorStatus(errors.New(c.Error))
+		case c.Done:
+			comp.Status = &status.Status{Code: int32(code.Code_OK)}
+		}
+		st.compensations = append(st.compensations, comp)
+	}
+	if !req.Done {
+		return st, nil
+	}
+	ep := s.registry.GetEndpoint(req.Name)
+	if ep == nil {
+		// The endpoint is no longer registered, so only the outcome is
+		// reported, without results.
+		st.status = outcomeStatus(req)
+		return st, nil
+	}
+	out, err := internal.DecodeValues(req.Results, ep.OutputTypes)
+	if err != nil {
+		return nil, err
+	}
+	if err := ep.GetError(out); err != nil {
+		st.status = errorStatus(err)
+		return st, nil
+	}
+	st.status = &status.Status{Code: int32(code.Code_OK)}
+	st.results, err = packResults(ep, out)
+	return st, err
+}
+
+// outcomeStatus returns the status of a completed request without decoding
+// its results. The error is the last result of every endpoint.
+func outcomeStatus(req *local.Request) *status.Status {
+	if !req.Failed {
+		return &status.Status{Code: int32(code.Code_OK)}
+	}
+	if n := len(req.Results); n > 0 {
+		v, _ := internal.DecodeValue(req.Results[n-1], registry.ErrorType)
+		if err, ok := v.Interface().(error); ok && err != nil {
+			return errorStatus(err)
+		}
+	}
+	return &status.Status{Code: int32(code.Code_UNKNOWN), Message: "operation failed"}
+}
+
+// errorStatus converts a stored error to a status. Errors are stored as
+// their message, so only context errors are recognized.
+func errorStatus(err error) *status.Status {
+	c := code.Code_UNKNOWN
+	switch {
+	case errors.Is(err, context.Canceled):
+		c = code.Code_CANCELLED
+	case errors.Is(err, context.DeadlineExceeded):
+		c = code.Code_DEADLINE_EXCEEDED
+	}
+	return &status.Status{Code: int32(c), Message: err.Error()}
+}
+
+// packResults packs the results of a call, other than the error. Results
+// which aren't protobuf messages are packed as google.protobuf.Value.
+func packResults(ep *registry.Endpoint, out []reflect.Value) ([]*anypb.Any, error) {
+	raw, err := ep.MarshalResults(out)
+	if err != nil {
+		return nil, err
+	}
+	var packed []*anypb.Any
+	for i, t := range ep.OutputTypes {
+		if t == registry.ErrorType {
+			continue
+		}
+		var msg proto.Message
+		switch {
+		case !internal.IsProtoMessage(t):
+			v := &structpb.Value{}
+			if err := protojson.Unmarshal(raw[len(packed)], v); err != nil {
+				return nil, fmt.Errorf("result %d: %w", i, err)
+			}
+			msg = v
+		case out[i].IsNil():
+			msg = reflect.New(t.Elem()).Interface().(proto.Message)
+		default:
+			msg = out[i].Interface().(proto.Message)
+		}
+		a, err := anypb.New(msg)
+		if err != nil {
+			return nil, fmt.Errorf("result %d: %w", i, err)
+		}
+		packed = append(packed, a)
+	}
+	return packed, nil
+}
+
+// toStruct converts a JSON Schema to a Struct.
+func toStruct(schema registry.Schema) (*structpb.Struct, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	st := &structpb.Struct{}
+	if err := protojson.Unmarshal(data, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
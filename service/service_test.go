@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/vgough/sequin"
+	sequinv1 "github.com/vgough/sequin/gen/sequin/v1"
+	"github.com/vgough/sequin/gen/sequin/v1/sequinv1connect"
+	"github.com/vgough/sequin/local"
+	"github.com/vgough/sequin/registry"
+)
+
+type testEnv struct {
+	client  sequinv1connect.SequinServiceClient
+	server  *local.Server
+	release chan struct{} // closes to unblock the blocking endpoint.
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	reg := registry.New()
+	env := &testEnv{release: make(chan struct{})}
+
+	square := sequin.RegisterIn(reg, func(_ context.Context, n int) (int, error) {
+		if n < 0 {
+			return 0, errors.New("negative input")
+		}
+		return n * n, nil
+	}, sequin.Name("service.test.square"), sequin.ArgNames("n"))
+	sequin.RegisterIn(reg, func(ctx context.Context, ns []int) (int, error) {
+		var total int
+		for _, n := range ns {
+			sq, err := square(ctx, n)
+			if err != nil {
+				return 0, err
+			}
+			total += sq
+		}
+		return total, nil
+	}, sequin.Name("service.test.sum-squares"))
+	sequin.RegisterIn(reg, func(ctx context.Context, s *wrapperspb.StringValue) (*wrapperspb.Int64Value, error) {
+		return wrapperspb.Int64(int64(len(s.GetValue()))), nil
+	}, sequin.Name("service.test.length"))
+	sequin.RegisterIn(reg, func(ctx context.Context, name string) (string, error) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-env.release:
+			return "released " + name, nil
+		}
+	}, sequin.Name("service.test.block"))
+
+	env.server = local.NewServer(local.WithRegistry(reg))
+	mux := http.NewServeMux()
+	mux.Handle(sequinv1connect.NewSequinServiceHandler(New(env.server, WithPollInterval(10*time.Millisecond))))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	env.client = sequinv1connect.NewSequinServiceClient(srv.Client(), srv.URL)
+	return env
+}
+
+func jsonOp(t *testing.T, name string, args ...any) *anypb.Any {
+	list, err := structpb.NewList(args)
+	require.NoError(t, err)
+	op, err := anypb.New(&sequinv1.JSONOperation{Name: name, Args: structpb.NewListValue(list)})
+	require.NoError(t, err)
+	return op
+}
+
+func (env *testEnv) start(t *testing.T, op *anypb.Any) string {
+	resp, err := env.client.Start(context.Background(), connect.NewRequest(&sequinv1.StartRequest{Operation: op}))
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Msg.GetRequestId())
+	return resp.Msg.GetRequestId()
+}
+
+// await long-polls until the operation completes.
+func (env *testEnv) await(t *testing.T, id string) *sequinv1.GetResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var last string
+	for {
+		resp, err := env.client.Get(ctx, connect.NewRequest(&sequinv1.GetRequest{RequestId: id, LastUpdateId: last}))
+		require.NoError(t, err)
+		if resp.Msg.GetLastUpdateId() == updateDone {
+			return resp.Msg
+		}
+		last = resp.Msg.GetLastUpdateId()
+	}
+}
+
+func TestService(t *testing.T) {
+	env := newTestEnv(t)
+
+	id := env.start(t, jsonOp(t, "service.test.sum-squares", []any{1, 2, 3}))
+	resp := env.await(t, id)
+	require.Equal(t, int32(code.Code_OK), resp.GetMetadata().GetStatus().GetCode())
+	require.Len(t, resp.GetResults(), 1)
+	var v structpb.Value
+	require.NoError(t, resp.GetResults()[0].UnmarshalTo(&v))
+	require.EqualValues(t, 14, v.GetNumberValue())
+
+	// Starting the same operation returns the same request.
+	require.Equal(t, id, env.start(t, jsonOp(t, "service.test.sum-squares", []any{1, 2, 3})))
+
+	// Named arguments.
+	args, err := structpb.NewValue(map[string]any{"n": 4})
+	require.NoError(t, err)
+	named, err := anypb.New(&sequinv1.JSONOperation{Name: "service.test.square", Args: args})
+	require.NoError(t, err)
+	resp = env.await(t, env.start(t, named))
+	require.NoError(t, resp.GetResults()[0].UnmarshalTo(&v))
+	require.EqualValues(t, 16, v.GetNumberValue())
+
+	// Protobuf-native endpoints return messages directly.
+	arg, err := anypb.New(wrapperspb.String("hello"))
+	require.NoError(t, err)
+	fn, err := anypb.New(&sequinv1.FuncOperation{Name: "service.test.length", Args: []*anypb.Any{arg}})
+	require.NoError(t, err)
+	resp = env.await(t, env.start(t, fn))
+	var n wrapperspb.Int64Value
+	require.NoError(t, resp.GetResults()[0].UnmarshalTo(&n))
+	require.EqualValues(t, 5, n.GetValue())
+
+	// Labels of the request are reported in the metadata.
+	started, err := env.client.Start(context.Background(), connect.NewRequest(&sequinv1.StartRequest{
+		Operation: jsonOp(t, "service.test.square", 5),
+		Metadata:  &sequinv1.RequestMetadata{Labels: map[string]string{"team": "infra"}},
+	}))
+	require.NoError(t, err)
+	resp = env.await(t, started.Msg.GetRequestId())
+	require.Equal(t, map[string]string{"team": "infra"}, resp.GetMetadata().GetLabels())
+
+	// Failures are reported in the status.
+	resp = env.await(t, env.start(t, jsonOp(t, "service.test.sum-squares", []any{1, -2})))
+	require.Equal(t, int32(code.Code_UNKNOWN), resp.GetMetadata().GetStatus().GetCode())
+	require.Equal(t, "negative input", resp.GetMetadata().GetStatus().GetMessage())
+	require.Empty(t, resp.GetResults())
+}
+
+func TestService_Errors(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	_, err := env.client.Start(ctx, connect.NewRequest(&sequinv1.StartRequest{
+		Operation: jsonOp(t, "service.test.unknown"),
+	}))
+	require.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+
+	_, err = env.client.Start(ctx, connect.NewRequest(&sequinv1.StartRequest{
+		Operation: jsonOp(t, "service.test.square", "x"),
+	}))
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+
+	op, err := anypb.New(wrapperspb.String("not an operation"))
+	require.NoError(t, err)
+	_, err = env.client.Start(ctx, connect.NewRequest(&sequinv1.StartRequest{Operation: op}))
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+
+	_, err = env.client.Get(ctx, connect.NewRequest(&sequinv1.GetRequest{RequestId: "missing"}))
+	require.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+
+	// Request IDs are required.
+	_, err = env.client.Cancel(ctx, connect.NewRequest(&sequinv1.CancelRequest{}))
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	_, err = env.client.GetCallTree(ctx, connect.NewRequest(&sequinv1.GetCallTreeRequest{}))
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	watch, err := env.client.Watch(ctx, connect.NewRequest(&sequinv1.WatchRequest{}))
+	require.NoError(t, err)
+	require.False(t, watch.Receive())
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(watch.Err()))
+}
+
+func TestService_Exec(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	stream, err := env.client.Exec(ctx, connect.NewRequest(&sequinv1.ExecRequest{
+		Operation: jsonOp(t, "service.test.block", "a"),
+	}))
+	require.NoError(t, err)
+	require.True(t, stream.Receive())
+	require.False(t, stream.Msg().GetDone())
+	id := stream.Msg().GetName()
+
+	// Watching the running operation also streams its completion.
+	watch, err := env.client.Watch(ctx, connect.NewRequest(&sequinv1.WatchRequest{RequestId: id}))
+	require.NoError(t, err)
+	require.True(t, watch.Receive())
+	require.False(t, watch.Msg().GetDone())
+
+	close(env.release)
+	for _, s := range []interface {
+		Receive() bool
+		Msg() *longrunningpb.Operation
+		Err() error
+	}{stream, watch} {
+		require.True(t, s.Receive())
+		op := s.Msg()
+		require.True(t, op.GetDone())
+		var resp sequinv1.ExecResponse
+		require.NoError(t, op.GetResponse().UnmarshalTo(&resp))
+		var v structpb.Value
+		require.NoError(t, resp.GetResults()[0].UnmarshalTo(&v))
+		require.Equal(t, "released a", v.GetStringValue())
+		require.False(t, s.Receive())
+		require.NoError(t, s.Err())
+	}
+}
+
+func TestService_Cancel(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	id := env.start(t, jsonOp(t, "service.test.block", "b"))
+	require.Eventually(t, func() bool {
+		_, err := env.client.Cancel(ctx, connect.NewRequest(&sequinv1.CancelRequest{RequestId: id}))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	resp := env.await(t, id)
+	require.Equal(t, int32(code.Code_CANCELLED), resp.GetMetadata().GetStatus().GetCode())
+
+	_, err := env.client.Cancel(ctx, connect.NewRequest(&sequinv1.CancelRequest{RequestId: id}))
+	require.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+}
+
+func TestService_ListEndpoints(t *testing.T) {
+	env := newTestEnv(t)
+	resp, err := env.client.ListEndpoints(context.Background(), connect.NewRequest(&sequinv1.ListEndpointsRequest{}))
+	require.NoError(t, err)
+
+	var names []string
+	for _, ep := range resp.Msg.GetEndpoints() {
+		names = append(names, ep.GetName())
+	}
+	require.Equal(t, []string{"service.test.block", "service.test.length",
+		"service.test.square", "service.test.sum-squares"}, names)
+
+	square := resp.Msg.GetEndpoints()[2]
+	require.Equal(t, []string{"n"}, square.GetArgNames())
+	require.Equal(t, "array", square.GetArgsSchema().GetFields()["type"].GetStringValue())
+}
+
+func TestService_GetCallTree(t *testing.T) {
+	env := newTestEnv(t)
+	id := env.start(t, jsonOp(t, "service.test.sum-squares", []any{2, -1}))
+	env.await(t, id)
+
+	resp, err := env.client.GetCallTree(context.Background(), connect.NewRequest(&sequinv1.GetCallTreeRequest{RequestId: id}))
+	require.NoError(t, err)
+	root := resp.Msg.GetRoot()
+	require.Equal(t, "service.test.sum-squares", root.GetName())
+	require.True(t, root.GetDone())
+	require.Equal(t, "negative input", root.GetStatus().GetMessage())
+	require.Len(t, root.GetChildren(), 2)
+	for i, child := range root.GetChildren() {
+		require.Equal(t, "service.test.square", child.GetName())
+		require.True(t, child.GetDone())
+		require.Equal(t, i == 1, child.GetStatus().GetCode() != int32(code.Code_OK))
+	}
+	require.False(t, proto.Equal(root.GetChildren()[0], root.GetChildren()[1]))
+}
+
+func TestService_ListOperations(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	start := func(labels map[string]string, ns ...any) string {
+		resp, err := env.client.Start(ctx, connect.NewRequest(&sequinv1.StartRequest{
+			Operation: jsonOp(t, "service.test.sum-squares", ns),
+			Metadata:  &sequinv1.RequestMetadata{Labels: labels},
+		}))
+		require.NoError(t, err)
+		env.await(t, resp.Msg.GetRequestId())
+		return resp.Msg.GetRequestId()
+	}
+	ok := start(map[string]string{"run": "a"}, 1, 2)
+	failed := start(map[string]string{"run": "b"}, 3, -1)
+
+	list := func(req *sequinv1.ListOperationsRequest) *sequinv1.ListOperationsResponse {
+		resp, err := env.client.ListOperations(ctx, connect.NewRequest(req))
+		require.NoError(t, err)
+		return resp.Msg
+	}
+
+	resp := list(&sequinv1.ListOperationsRequest{
+		Name:   "service.test.sum-squares",
+		Status: sequinv1.OperationStatus_OPERATION_STATUS_FAILED,
+	})
+	require.Len(t, resp.GetOperations(), 1)
+	op := resp.GetOperations()[0]
+	require.Equal(t, failed, op.GetName())
+	require.True(t, op.GetDone())
+	require.Equal(t, "negative input", op.GetError().GetMessage())
+	var md sequinv1.RunMetadata
+	require.NoError(t, op.GetMetadata().UnmarshalTo(&md))
+	require.Equal(t, "service.test.sum-squares", md.GetEndpoint())
+	require.Equal(t, map[string]string{"run": "b"}, md.GetLabels())
+	require.NotNil(t, md.GetSubmittedAt())
+	require.False(t, md.GetFinishedAt().AsTime().Before(md.GetStartedAt().AsTime()))
+
+	resp = list(&sequinv1.ListOperationsRequest{Labels: map[string]string{"run": "a"}, TopLevel: true})
+	require.Len(t, resp.GetOperations(), 1)
+	require.Equal(t, ok, resp.GetOperations()[0].GetName())
+
+	// Pages of one operation, most recent first.
+	resp = list(&sequinv1.ListOperationsRequest{TopLevel: true, PageSize: 1})
+	require.Equal(t, failed, resp.GetOperations()[0].GetName())
+	require.NotEmpty(t, resp.GetNextPageToken())
+	resp = list(&sequinv1.ListOperationsRequest{TopLevel: true, PageSize: 1, PageToken: resp.GetNextPageToken()})
+	require.Equal(t, ok, resp.GetOperations()[0].GetName())
+	require.Empty(t, resp.GetNextPageToken())
+
+	resp = list(&sequinv1.ListOperationsRequest{SubmittedBefore: timestamppb.New(time.Now().Add(-time.Hour))})
+	require.Empty(t, resp.GetOperations())
+
+	for _, req := range []*sequinv1.ListOperationsRequest{
+		{PageToken: "invalid"},
+		{PageSize: -1},
+		{Status: 42},
+	} {
+		_, err := env.client.ListOperations(ctx, connect.NewRequest(req))
+		require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err), req)
+	}
+}
+
+func TestService_Compensations(t *testing.T) {
+	reg := registry.New()
+	step := sequin.RegisterIn(reg, func(_ context.Context, n int) error {
+		return nil
+	}, sequin.Name("service.test.step"), sequin.Compensate("service.test.undo"))
+	sequin.RegisterIn(reg, func(_ context.Context, n int) error {
+		if n == 2 {
+			return errors.New("undo failed")
+		}
+		return nil
+	}, sequin.Name("service.test.undo"))
+	workflow := sequin.RegisterIn(reg, func(ctx context.Context) error {
+		for n := range 3 {
+			if err := step(ctx, n); err != nil {
+				return err
+			}
+		}
+		return errors.New("failed")
+	}, sequin.Name("service.test.workflow"))
+
+	server := local.NewServer(local.WithRegistry(reg))
+	ctx := sequin.WithRuntime(context.Background(), server)
+	require.EqualError(t, workflow(ctx), "failed")
+	ep := reg.GetEndpoint("service.test.workflow")
+	args := []reflect.Value{{}}
+	ep.SetContext(ctx, args)
+	id, err := server.RequestID(ep, args)
+	require.NoError(t, err)
+
+	st, err := New(server).state(ctx, id)
+	require.NoError(t, err)
+	comps := st.metadata().GetCompensations()
+	require.Len(t, comps, 3)
+	for i, c := range comps {
+		require.Equal(t, "service.test.undo", c.GetEndpoint())
+		require.NotEmpty(t, c.GetStep())
+		require.NotEmpty(t, c.GetRequestId())
+		require.True(t, c.GetDone())
+		if i == 2 {
+			require.Equal(t, "undo failed", c.GetStatus().GetMessage())
+		} else {
+			require.Equal(t, int32(code.Code_OK), c.GetStatus().GetCode())
+		}
+	}
+}
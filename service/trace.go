@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewTraceInterceptor returns an interceptor which propagates trace context
+// in request headers, so that operations started by a client are traced as
+// part of the client's trace. Clients inject the trace context of the call,
+// and handlers extract it.
+//
+// If p is nil, the W3C Trace Context format is used.
+func NewTraceInterceptor(p propagation.TextMapPropagator) connect.Interceptor {
+	if p == nil {
+		p = propagation.TraceContext{}
+	}
+	return &traceInterceptor{p: p}
+}
+
+type traceInterceptor struct {
+	p propagation.TextMapPropagator
+}
+
+func (ti *traceInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			ti.p.Inject(ctx, propagation.HeaderCarrier(req.Header()))
+		} else {
+			ctx = ti.p.Extract(ctx, propagation.HeaderCarrier(req.Header()))
+		}
+		return next(ctx, req)
+	}
+}
+
+func (ti *traceInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := next(ctx, spec)
+		ti.p.Inject(ctx, propagation.HeaderCarrier(conn.RequestHeader()))
+		return conn
+	}
+}
+
+func (ti *traceInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		return next(ti.p.Extract(ctx, propagation.HeaderCarrier(conn.RequestHeader())), conn)
+	}
+}
+
+// detach returns a context for executing an operation in the background,
+// which continues the trace of the request that started it.
+func detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+}
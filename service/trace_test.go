@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/vgough/sequin"
+	sequinv1 "github.com/vgough/sequin/gen/sequin/v1"
+	"github.com/vgough/sequin/gen/sequin/v1/sequinv1connect"
+	"github.com/vgough/sequin/local"
+	"github.com/vgough/sequin/registry"
+)
+
+func TestTraceInterceptor(t *testing.T) {
+	reg := registry.New()
+	sequin.RegisterIn(reg, func(_ context.Context, n int) (int, error) {
+		return n * n, nil
+	}, sequin.Name("service.test.square"))
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	svc := New(local.NewServer(local.WithRegistry(reg), local.WithTracerProvider(tp)))
+	srv := httptest.NewServer(svc.Handler(connect.WithInterceptors(NewTraceInterceptor(nil))))
+	t.Cleanup(srv.Close)
+	client := sequinv1connect.NewSequinServiceClient(srv.Client(), srv.URL,
+		connect.WithInterceptors(NewTraceInterceptor(nil)))
+
+	ctx, root := tp.Tracer("test").Start(context.Background(), "client")
+	defer root.End()
+
+	// Operations started by unary and streaming calls continue the client's
+	// trace.
+	_, err := client.Start(ctx, connect.NewRequest(&sequinv1.StartRequest{Operation: jsonOp(t, "service.test.square", 2)}))
+	require.NoError(t, err)
+	stream, err := client.Exec(ctx, connect.NewRequest(&sequinv1.ExecRequest{Operation: jsonOp(t, "service.test.square", 3)}))
+	require.NoError(t, err)
+	for stream.Receive() {
+	}
+	require.NoError(t, stream.Err())
+
+	require.Eventually(t, func() bool {
+		return len(rec.Ended()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	for _, span := range rec.Ended() {
+		require.Equal(t, "service.test.square", span.Name())
+		require.Equal(t, root.SpanContext().TraceID(), span.SpanContext().TraceID())
+		require.Equal(t, root.SpanContext().SpanID(), span.Parent().SpanID())
+		require.True(t, span.Parent().IsRemote())
+	}
+}
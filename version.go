@@ -0,0 +1,63 @@
+package sequin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vgough/sequin/registry"
+)
+
+// DefaultVersion is returned by GetVersion for executions which passed the
+// branch point before it was added to the code.
+const DefaultVersion = -1
+
+// ErrIncompatibleVersion is returned when a recorded execution can't be
+// replayed by the current code.
+var ErrIncompatibleVersion = errors.New("incompatible version")
+
+// Version declares the version of the endpoint.
+//
+// The runtime refuses to resume executions which were started by a different
+// version of the endpoint. Increment the version when making changes which
+// can't be handled by branching on GetVersion.
+func Version(v int) RegisterOpt {
+	return func(ep *registry.Endpoint) error {
+		ep.Version = v
+		return nil
+	}
+}
+
+// Versioner is implemented by runtimes which durably record branch points.
+type Versioner interface {
+	GetVersion(ctx context.Context, changeID string, min, max int) (int, error)
+}
+
+// GetVersion returns the version of the code to run at a branch point, which
+// allows changing a workflow without breaking executions that are in flight.
+//
+// The first time an execution reaches the branch point, max is returned and
+// recorded. When the execution is replayed, the recorded version is returned.
+// Executions which passed the branch point before it was added to the code
+// return DefaultVersion.
+//
+// An error is returned if the recorded version is outside of [min, max].
+// Use DefaultVersion as min to continue supporting executions which predate
+// the change.
+//
+//	v, err := sequin.GetVersion(ctx, "add-audit-step", sequin.DefaultVersion, 1)
+//	if err != nil {
+//		return err
+//	}
+//	if v >= 1 {
+//		// new code
+//	}
+func GetVersion(ctx context.Context, changeID string, min, max int) (int, error) {
+	if min > max {
+		return 0, fmt.Errorf("invalid version range [%d, %d] for change %q", min, max, changeID)
+	}
+	if v, ok := GetRuntime(ctx).(Versioner); ok {
+		return v.GetVersion(ctx, changeID, min, max)
+	}
+	return max, nil
+}